@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// ActiveTermTTL is how long a term pinned with /setterm stays active after it was set
+const ActiveTermTTL = 24 * time.Hour
+
+// SetActiveTerm pins the term for the user's commands within the channel
+func SetActiveTerm(ctx context.Context, channelID string, userID string, term Term) error {
+	err := kv.Set(ctx, ActiveTermKey(channelID, userID), term.ToString(), ActiveTermTTL).Err()
+	if err != nil {
+		return fmt.Errorf("failed to set active term: %w", err)
+	}
+	return nil
+}
+
+// ClearActiveTerm unpins the user's term within the channel, returning them to the default term
+func ClearActiveTerm(ctx context.Context, channelID string, userID string) error {
+	err := kv.Del(ctx, ActiveTermKey(channelID, userID)).Err()
+	if err != nil {
+		return fmt.Errorf("failed to clear active term: %w", err)
+	}
+	return nil
+}
+
+// GetActiveTerm returns the term pinned by the user within the channel, or nil if none is pinned
+func GetActiveTerm(ctx context.Context, channelID string, userID string) (*Term, error) {
+	code, err := kv.Get(ctx, ActiveTermKey(channelID, userID)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get active term: %w", err)
+	}
+
+	if !IsValidTermCode(code) {
+		return nil, fmt.Errorf("invalid active term code: %s", code)
+	}
+
+	term := ParseTerm(code)
+	return &term, nil
+}
+
+// ResolveTerm returns the term the interaction's commands should use: the term pinned by the user
+// within the channel if there is one, otherwise the default term.
+func ResolveTerm(ctx context.Context, i *discordgo.InteractionCreate) Term {
+	term, err := GetActiveTerm(ctx, i.ChannelID, GetUser(i).ID)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to resolve active term, using the default term")
+	}
+
+	if term == nil {
+		return Default(time.Now())
+	}
+	return *term
+}
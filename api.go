@@ -2,35 +2,56 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
-
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
 )
 
 var (
 	latestSession string = ""
+	sessionTerm   string // The term currently selected within the latest session
 	sessionTime   time.Time
 	expiryTime    time.Duration = 25 * time.Minute
+
+	// sessionLock guards latestSession & sessionTerm, and is held while the session's term is being selected
+	sessionLock sync.Mutex
+	// sessionTimeLock guards sessionTime separately, as it is reset from within DoRequest while sessionLock is held
+	sessionTimeLock sync.Mutex
+	// searchLock serializes searches, as the selected term & search form are shared by every search using the session
+	searchLock sync.Mutex
 )
 
 // ResetSessionTimer resets the session timer to the current time.
 // This is only used by the DoRequest handler when Banner API calls are detected, which would reset the session timer.
 func ResetSessionTimer() {
+	sessionTimeLock.Lock()
+	defer sessionTimeLock.Unlock()
+
 	// Only reset the session time if the session is still valid
 	if time.Since(sessionTime) <= expiryTime {
 		sessionTime = time.Now()
 	}
 }
 
+// sessionExpired returns true if the session timer has run out.
+func sessionExpired() bool {
+	sessionTimeLock.Lock()
+	defer sessionTimeLock.Unlock()
+	return time.Since(sessionTime) >= expiryTime
+}
+
 // GenerateSession generates a new session ID (nonce) for use with the Banner API.
 // Don't use this function directly, use GetSession instead.
 func GenerateSession() string {
@@ -40,24 +61,52 @@ func GenerateSession() string {
 // GetSession retrieves the current session ID if it's still valid.
 // If the session ID is invalid or has expired, a new one is generated and returned.
 // SessionIDs are valid for 30 minutes, but we'll be conservative and regenerate every 25 minutes.
-func GetSession() string {
+func GetSession(ctx context.Context) (string, error) {
+	sessionLock.Lock()
+	defer sessionLock.Unlock()
+
 	// Check if a reset is required
-	if latestSession == "" || time.Since(sessionTime) >= expiryTime {
+	if latestSession == "" || sessionExpired() {
 		// Generate a new session identifier
-		latestSession = GenerateSession()
+		sessionID := GenerateSession()
 
 		// Select the current term
 		term := Default(time.Now()).ToString()
-		log.Info().Str("term", term).Str("sessionID", latestSession).Msg("Setting selected term")
-		err := SelectTerm(term, latestSession)
+		log.Ctx(ctx).Info().Str("term", term).Str("sessionID", sessionID).Msg("Setting selected term")
+		err := SelectTerm(ctx, term, sessionID)
 		if err != nil {
-			log.Fatal().Stack().Err(err).Msg("Failed to select term while generating session ID")
+			return "", fmt.Errorf("failed to select term while generating session ID: %w", err)
 		}
 
+		latestSession = sessionID
+		sessionTerm = term
+		sessionTimeLock.Lock()
 		sessionTime = time.Now()
+		sessionTimeLock.Unlock()
+	}
+
+	return latestSession, nil
+}
+
+// EnsureSessionTerm selects the term within the latest session, if it is not already selected.
+// Sessions only search within their selected term, so this must precede searches of other terms.
+// The selected term is shared by every search using the session, so callers must hold searchLock until their search completes.
+func EnsureSessionTerm(ctx context.Context, sessionID string, term string) error {
+	sessionLock.Lock()
+	defer sessionLock.Unlock()
+
+	if sessionID == latestSession && term == sessionTerm {
+		return nil
 	}
 
-	return latestSession
+	log.Ctx(ctx).Debug().Str("term", term).Str("sessionID", sessionID).Msg("Switching selected term")
+	err := SelectTerm(ctx, term, sessionID)
+	if err != nil {
+		return err
+	}
+
+	sessionTerm = term
+	return nil
 }
 
 type Pair struct {
@@ -73,15 +122,40 @@ func (term BannerTerm) Archived() bool {
 	return strings.Contains(term.Description, "View Only")
 }
 
+// Name returns the term's description without the archival suffix (e.g. "Fall 2024 (View Only)" => "Fall 2024")
+func (term BannerTerm) Name() string {
+	return strings.TrimSpace(strings.TrimSuffix(term.Description, "(View Only)"))
+}
+
+// TermsResult is a single page of terms returned by GetTerms, along with the pagination state of the request.
+// Banner does not provide a total count for terms, so whether more pages exist is inferred from a full page.
+type TermsResult struct {
+	Terms    []BannerTerm
+	Page     int
+	PageSize int
+	// HasMore is true if the page was full, implying that another page may exist
+	HasMore bool
+}
+
+// Total returns the total number of terms matching the search, if it can be determined.
+// The total is only known once the last page has been reached.
+func (r *TermsResult) Total() (int, bool) {
+	// An empty page past the first only shows that the end was passed, not where it is
+	if r.HasMore || (len(r.Terms) == 0 && r.Page > 1) {
+		return 0, false
+	}
+	return (r.Page-1)*r.PageSize + len(r.Terms), true
+}
+
 // GetTerms retrieves and parses the term information for a given search term.
 // Page number must be at least 1.
-func GetTerms(search string, page int, max int) ([]BannerTerm, error) {
+func GetTerms(ctx context.Context, search string, page int, max int) (*TermsResult, error) {
 	// Ensure offset is valid
 	if page <= 0 {
 		return nil, errors.New("offset must be greater than 0")
 	}
 
-	req := BuildRequest("GET", "/classSearch/getTerms", map[string]string{
+	req := BuildRequest(ctx, "GET", "/classSearch/getTerms", map[string]string{
 		"searchTerm": search,
 		// Page vs Offset is not a mistake here, the API uses "offset" as the page number
 		"offset": strconv.Itoa(page),
@@ -89,10 +163,6 @@ func GetTerms(search string, page int, max int) ([]BannerTerm, error) {
 		"_":      Nonce(),
 	})
 
-	if page <= 0 {
-		return nil, errors.New("Offset must be greater than 0")
-	}
-
 	res, err := DoRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get terms: %w", err)
@@ -114,18 +184,22 @@ func GetTerms(search string, page int, max int) ([]BannerTerm, error) {
 	}
 
 	terms := make([]BannerTerm, 0, 10)
-	json.Unmarshal(body, &terms)
-
+	err = json.Unmarshal(body, &terms)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse terms: %w", err)
 	}
 
-	return terms, nil
+	return &TermsResult{
+		Terms:    terms,
+		Page:     page,
+		PageSize: max,
+		HasMore:  len(terms) >= max,
+	}, nil
 }
 
 // SelectTerm selects the given term in the Banner system.
 // This function completes the initial term selection process, which is required before any other API calls can be made with the session ID.
-func SelectTerm(term string, sessionId string) error {
+func SelectTerm(ctx context.Context, term string, sessionId string) error {
 	form := url.Values{
 		"term":            {term},
 		"studyPath":       {""},
@@ -139,7 +213,7 @@ func SelectTerm(term string, sessionId string) error {
 		"mode": "search",
 	}
 
-	req := BuildRequestWithBody("POST", "/term/search", params, bytes.NewBufferString(form.Encode()))
+	req := BuildRequestWithBody(ctx, "POST", "/term/search", params, bytes.NewBufferString(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	res, err := DoRequest(req)
@@ -149,7 +223,7 @@ func SelectTerm(term string, sessionId string) error {
 
 	// Assert that the response is JSON
 	if !ContentTypeMatch(res, "application/json") {
-		return fmt.Errorf("response was not JSON: %w", res.Header.Get("Content-Type"))
+		return fmt.Errorf("response was not JSON: %s", res.Header.Get("Content-Type"))
 	}
 
 	// Acquire fwdUrl
@@ -165,7 +239,7 @@ func SelectTerm(term string, sessionId string) error {
 	json.Unmarshal(body, &redirectResponse)
 
 	// Make a GET request to the fwdUrl
-	req = BuildRequest("GET", redirectResponse.FwdUrl, nil)
+	req = BuildRequest(ctx, "GET", redirectResponse.FwdUrl, nil)
 	res, err = DoRequest(req)
 	if err != nil {
 		return fmt.Errorf("failed to follow redirect: %w", err)
@@ -173,7 +247,7 @@ func SelectTerm(term string, sessionId string) error {
 
 	// Assert that the response is OK (200)
 	if res.StatusCode != 200 {
-		return fmt.Errorf("redirect response was not 200: %w", res.StatusCode)
+		return fmt.Errorf("redirect response was not 200: %d", res.StatusCode)
 	}
 
 	return nil
@@ -181,18 +255,23 @@ func SelectTerm(term string, sessionId string) error {
 
 // GetPartOfTerms retrieves and parses the part of term information for a given term.
 // Ensure that the offset is greater than 0.
-func GetPartOfTerms(search string, term int, offset int, max int) ([]BannerTerm, error) {
+func GetPartOfTerms(ctx context.Context, search string, term int, offset int, max int) ([]BannerTerm, error) {
 	// Ensure offset is valid
 	if offset <= 0 {
 		return nil, errors.New("offset must be greater than 0")
 	}
 
-	req := BuildRequest("GET", "/classSearch/get_partOfTerm", map[string]string{
+	sessionID, err := GetSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := BuildRequest(ctx, "GET", "/classSearch/get_partOfTerm", map[string]string{
 		"searchTerm":      search,
 		"term":            strconv.Itoa(term),
 		"offset":          strconv.Itoa(offset),
 		"max":             strconv.Itoa(max),
-		"uniqueSessionId": GetSession(),
+		"uniqueSessionId": sessionID,
 		"_":               Nonce(),
 	})
 
@@ -225,18 +304,23 @@ func GetPartOfTerms(search string, term int, offset int, max int) ([]BannerTerm,
 // In my opinion, it is unclear what providing the term does, as the results should be the same regardless of the term.
 // This function is included for completeness, but probably isn't useful.
 // Ensure that the offset is greater than 0.
-func GetInstructors(search string, term string, offset int, max int) ([]Instructor, error) {
+func GetInstructors(ctx context.Context, search string, term string, offset int, max int) ([]Instructor, error) {
 	// Ensure offset is valid
 	if offset <= 0 {
 		return nil, errors.New("offset must be greater than 0")
 	}
 
-	req := BuildRequest("GET", "/classSearch/get_instructor", map[string]string{
+	sessionID, err := GetSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := BuildRequest(ctx, "GET", "/classSearch/get_instructor", map[string]string{
 		"searchTerm":      search,
 		"term":            term,
 		"offset":          strconv.Itoa(offset),
 		"max":             strconv.Itoa(max),
-		"uniqueSessionId": GetSession(),
+		"uniqueSessionId": sessionID,
 		"_":               Nonce(),
 	})
 
@@ -270,7 +354,7 @@ func GetInstructors(search string, term string, offset int, max int) ([]Instruct
 type ClassDetails struct {
 }
 
-func GetCourseDetails(term int, crn int) *ClassDetails {
+func GetCourseDetails(ctx context.Context, term int, crn int) *ClassDetails {
 	body, err := json.Marshal(map[string]string{
 		"term":                  strconv.Itoa(term),
 		"courseReferenceNumber": strconv.Itoa(crn),
@@ -279,7 +363,7 @@ func GetCourseDetails(term int, crn int) *ClassDetails {
 	if err != nil {
 		log.Fatal().Stack().Err(err).Msg("Failed to marshal body")
 	}
-	req := BuildRequestWithBody("GET", "/searchResults/getClassDetails", nil, bytes.NewBuffer(body))
+	req := BuildRequestWithBody(ctx, "GET", "/searchResults/getClassDetails", nil, bytes.NewBuffer(body))
 
 	res, err := DoRequest(req)
 	if err != nil {
@@ -294,14 +378,35 @@ func GetCourseDetails(term int, crn int) *ClassDetails {
 	return &ClassDetails{}
 }
 
+// MaxSearchQueryLength is the longest encoded search query sent as URL parameters; longer queries are sent as a POST form
+const MaxSearchQueryLength = 1500
+
 // Search invokes a search on the Banner system with the given query and returns the results.
-func Search(query *Query, sort string, sortDescending bool) (*SearchResult, error) {
-	ResetDataForm()
+// Searches are made one at a time, as another search could otherwise switch the session's term or form state mid-search.
+func Search(ctx context.Context, query *Query, sort string, sortDescending bool) (*SearchResult, error) {
+	searchLock.Lock()
+	defer searchLock.Unlock()
+
+	sessionID, err := GetSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	term := query.TermCode()
+	err = EnsureSessionTerm(ctx, sessionID, term)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ResetDataFormIfRequired(ctx, sessionID, query, sort)
+	if err != nil {
+		return nil, err
+	}
 
 	params := query.Paramify()
 
-	params["txt_term"] = "202510" // TODO: Make this automatic but dynamically specifiable
-	params["uniqueSessionId"] = GetSession()
+	params["txt_term"] = term
+	params["uniqueSessionId"] = sessionID
 	params["sortColumn"] = sort
 	params["sortDirection"] = "asc"
 
@@ -309,7 +414,19 @@ func Search(query *Query, sort string, sortDescending bool) (*SearchResult, erro
 	params["startDatepicker"] = ""
 	params["endDatepicker"] = ""
 
-	req := BuildRequest("GET", "/searchResults/searchResults", params)
+	// Large parameter sets are sent as a form body, as long URLs risk being truncated
+	var req *http.Request
+	form := url.Values{}
+	for key, value := range params {
+		form.Set(key, value)
+	}
+	encoded := form.Encode()
+	if len(encoded) > MaxSearchQueryLength {
+		req = BuildRequestWithBody(ctx, "POST", "/searchResults/searchResults", nil, strings.NewReader(encoded))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = BuildRequest(ctx, "GET", "/searchResults/searchResults", params)
+	}
 
 	res, err := DoRequest(req)
 	if err != nil {
@@ -342,21 +459,74 @@ func Search(query *Query, sort string, sortDescending bool) (*SearchResult, erro
 	return &result, nil
 }
 
+// CachedSearch behaves like Search, but serves identical searches from Redis for a short period of time.
+// Open-only searches are cached for a shorter period, as seat availability changes quickly.
+// Call Search directly to bypass the cache.
+func CachedSearch(ctx context.Context, query *Query, sort string, sortDescending bool) (*SearchResult, error) {
+	if searchCacheTTL <= 0 {
+		return Search(ctx, query, sort, sortDescending)
+	}
+
+	key := SearchCacheKey(query.TermCode(), query, sort, sortDescending)
+
+	// Check for a cached result
+	cached, err := kv.Get(ctx, key).Result()
+	if err == nil {
+		var result SearchResult
+		err = json.Unmarshal([]byte(cached), &result)
+		if err == nil {
+			log.Ctx(ctx).Debug().Str("key", key).Str("query", query.String()).Msg("Search cache hit")
+			return &result, nil
+		}
+		log.Warn().Err(err).Str("key", key).Msg("Failed to parse cached search result")
+	} else if err != redis.Nil {
+		log.Error().Stack().Err(err).Str("key", key).Msg("Failed to get cached search result")
+	}
+
+	result, err := Search(ctx, query, sort, sortDescending)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := searchCacheTTL
+	if query.openOnly != nil && *query.openOnly {
+		ttl = min(ttl, 30*time.Second)
+	}
+
+	// Cache the result, failure here is not fatal
+	raw, err := json.Marshal(result)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to marshal search result for caching")
+		return result, nil
+	}
+	err = kv.Set(ctx, key, raw, ttl).Err()
+	if err != nil {
+		log.Error().Stack().Err(err).Str("key", key).Msg("Failed to cache search result")
+	}
+
+	return result, nil
+}
+
 // GetSubjects retrieves and parses the subject information for a given search term.
 // The results of this response shouldn't change much, but technically could as new majors are developed, or old ones are removed.
 // Ensure that the offset is greater than 0.
-func GetSubjects(search string, term string, offset int, max int) ([]Pair, error) {
+func GetSubjects(ctx context.Context, search string, term string, offset int, max int) ([]Pair, error) {
 	// Ensure offset is valid
 	if offset <= 0 {
 		return nil, errors.New("offset must be greater than 0")
 	}
 
-	req := BuildRequest("GET", "/classSearch/get_subject", map[string]string{
+	sessionID, err := GetSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := BuildRequest(ctx, "GET", "/classSearch/get_subject", map[string]string{
 		"searchTerm":      search,
 		"term":            term,
 		"offset":          strconv.Itoa(offset),
 		"max":             strconv.Itoa(max),
-		"uniqueSessionId": GetSession(),
+		"uniqueSessionId": sessionID,
 		"_":               Nonce(),
 	})
 
@@ -389,18 +559,23 @@ func GetSubjects(search string, term string, offset int, max int) ([]Pair, error
 // In my opinion, it is unclear what providing the term does, as the results should be the same regardless of the term.
 // This function is included for completeness, but probably isn't useful.
 // Ensure that the offset is greater than 0.
-func GetCampuses(search string, term int, offset int, max int) ([]Pair, error) {
+func GetCampuses(ctx context.Context, search string, term int, offset int, max int) ([]Pair, error) {
 	// Ensure offset is valid
 	if offset <= 0 {
 		return nil, errors.New("offset must be greater than 0")
 	}
 
-	req := BuildRequest("GET", "/classSearch/get_campus", map[string]string{
+	sessionID, err := GetSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := BuildRequest(ctx, "GET", "/classSearch/get_campus", map[string]string{
 		"searchTerm":      search,
 		"term":            strconv.Itoa(term),
 		"offset":          strconv.Itoa(offset),
 		"max":             strconv.Itoa(max),
-		"uniqueSessionId": GetSession(),
+		"uniqueSessionId": sessionID,
 		"_":               Nonce(),
 	})
 
@@ -433,18 +608,23 @@ func GetCampuses(search string, term int, offset int, max int) ([]Pair, error) {
 // In my opinion, it is unclear what providing the term does, as the results should be the same regardless of the term.
 // This function is included for completeness, but probably isn't useful.
 // Ensure that the offset is greater than 0.
-func GetInstructionalMethods(search string, term string, offset int, max int) ([]Pair, error) {
+func GetInstructionalMethods(ctx context.Context, search string, term string, offset int, max int) ([]Pair, error) {
 	// Ensure offset is valid
 	if offset <= 0 {
 		return nil, errors.New("offset must be greater than 0")
 	}
 
-	req := BuildRequest("GET", "/classSearch/get_instructionalMethod", map[string]string{
+	sessionID, err := GetSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := BuildRequest(ctx, "GET", "/classSearch/get_instructionalMethod", map[string]string{
 		"searchTerm":      search,
 		"term":            term,
 		"offset":          strconv.Itoa(offset),
 		"max":             strconv.Itoa(max),
-		"uniqueSessionId": GetSession(),
+		"uniqueSessionId": sessionID,
 		"_":               Nonce(),
 	})
 
@@ -473,8 +653,8 @@ func GetInstructionalMethods(search string, term string, offset int, max int) ([
 // GetCourseMeetingTime retrieves the meeting time information for a course based on the given term and course reference number (CRN).
 // It makes an HTTP GET request to the appropriate API endpoint and parses the response to extract the meeting time data.
 // The function returns a MeetingTimeResponse struct containing the extracted information.
-func GetCourseMeetingTime(term int, crn int) ([]MeetingTimeResponse, error) {
-	req := BuildRequest("GET", "/searchResults/getFacultyMeetingTimes", map[string]string{
+func GetCourseMeetingTime(ctx context.Context, term int, crn int) ([]MeetingTimeResponse, error) {
+	req := BuildRequest(ctx, "GET", "/searchResults/getFacultyMeetingTimes", map[string]string{
 		"term":                  strconv.Itoa(term),
 		"courseReferenceNumber": strconv.Itoa(crn),
 	})
@@ -508,20 +688,61 @@ func GetCourseMeetingTime(term int, crn int) ([]MeetingTimeResponse, error) {
 	return meetingTime.Inner, nil
 }
 
+var (
+	// The session & search that the data form was last reset for
+	lastResetSession string
+	lastResetSearch  string
+	// Counters for the number of data form resets performed & skipped, used for logging
+	dataFormResets, dataFormSkips int
+	// dataFormLock guards the data form state above, and is held while the form is reset
+	dataFormLock sync.Mutex
+)
+
 // ResetDataForm makes a POST request that needs to be made upon before new search requests can be made.
-func ResetDataForm() {
-	req := BuildRequest("POST", "/classSearch/resetDataForm", nil)
+func ResetDataForm(ctx context.Context) error {
+	req := BuildRequest(ctx, "POST", "/classSearch/resetDataForm", nil)
 	_, err := DoRequest(req)
 	if err != nil {
-		log.Fatal().Stack().Err(err).Msg("Failed to reset data form")
+		return fmt.Errorf("failed to reset data form: %w", err)
+	}
+	return nil
+}
+
+// ResetDataFormIfRequired resets the data form only when a different search is being started.
+// Banner keeps the search form state per session, so the form must be reset for a new session, or when the query or sort changes.
+// Paging through the same search (only the offset differs) reuses the existing form state, so no reset is made.
+func ResetDataFormIfRequired(ctx context.Context, sessionID string, query *Query, sort string) error {
+	// Ignore the offset, as it is the only parameter that changes while paginating
+	unpaged := *query
+	unpaged.offset = 0
+	search := fmt.Sprintf("%s|%s", unpaged.String(), sort)
+
+	dataFormLock.Lock()
+	defer dataFormLock.Unlock()
+
+	if sessionID == lastResetSession && search == lastResetSearch {
+		dataFormSkips++
+		log.Ctx(ctx).Debug().Int("resets", dataFormResets).Int("skips", dataFormSkips).Msg("Skipping data form reset")
+		return nil
+	}
+
+	err := ResetDataForm(ctx)
+	if err != nil {
+		return err
 	}
+
+	lastResetSession = sessionID
+	lastResetSearch = search
+	dataFormResets++
+	log.Ctx(ctx).Debug().Int("resets", dataFormResets).Int("skips", dataFormSkips).Msg("Reset data form")
+	return nil
 }
 
 // GetCourse retrieves the course information.
 // This course does not retrieve directly from the API, but rather uses scraped data stored in Redis.
-func GetCourse(crn string) (*Course, error) {
+func GetCourse(ctx context.Context, crn string) (*Course, error) {
 	// Retrieve raw data
-	result, err := kv.Get(ctx, fmt.Sprintf("class:%s", crn)).Result()
+	result, err := kv.Get(ctx, ClassKey(crn)).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return nil, fmt.Errorf("course not found: %w", err)
@@ -538,3 +759,27 @@ func GetCourse(crn string) (*Course, error) {
 
 	return &course, nil
 }
+
+// FindCourseByCRN looks up a single course within the term by its CRN with a live search, falling back to scraped data.
+// Banner has no CRN filter, but keyword searches match CRNs, so the results are filtered to the exact CRN.
+func FindCourseByCRN(ctx context.Context, term string, crn string) (*Course, error) {
+	result, err := Search(ctx, NewQuery().Term(term).Keyword(crn).MaxResults(50), "", false)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("crn", crn).Msg("Live CRN search failed, falling back to scraped data")
+	} else if course, found := lo.Find(result.Data, func(course Course) bool {
+		return course.CourseReferenceNumber == crn
+	}); found {
+		return &course, nil
+	}
+
+	course, err := GetCourse(ctx, crn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Scraped courses are only kept for a single term
+	if course.Term != term {
+		return nil, fmt.Errorf("course not found in term %s", term)
+	}
+	return course, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestResetDataFormIfRequired(t *testing.T) {
+	// Answer the reset requests with canned responses, rather than sending them to Banner
+	dryRun = true
+	t.Cleanup(func() {
+		dryRun = false
+		lastResetSession, lastResetSearch = "", ""
+	})
+
+	base := func() *Query {
+		return NewQuery().Term("202510").Subject("CS").MaxResults(10)
+	}
+
+	steps := []struct {
+		name      string
+		sessionID string
+		query     *Query
+		sort      string
+		reset     bool
+	}{
+		{"first search", "session1", base(), "subjectDescription", true},
+		{"same search", "session1", base(), "subjectDescription", false},
+		{"next page", "session1", base().Offset(10), "subjectDescription", false},
+		{"later page", "session1", base().Offset(30), "subjectDescription", false},
+		{"different query", "session1", base().Title("Algorithms"), "subjectDescription", true},
+		{"back to the first query", "session1", base(), "subjectDescription", true},
+		{"different sort", "session1", base(), "courseTitle", true},
+		{"different term", "session1", base().Term("202520"), "courseTitle", true},
+		{"different session", "session2", base().Term("202520"), "courseTitle", true},
+		{"different page size", "session2", base().Term("202520").MaxResults(25), "courseTitle", true},
+	}
+
+	lastResetSession, lastResetSearch = "", ""
+	for _, step := range steps {
+		before := dataFormResets
+		err := ResetDataFormIfRequired(context.Background(), step.sessionID, step.query, step.sort)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+
+		if reset := dataFormResets > before; reset != step.reset {
+			t.Errorf("%s: reset = %t, want %t", step.name, reset, step.reset)
+		}
+	}
+}
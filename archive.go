@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ScrapedSearch searches the courses scraped for the query's term, without contacting Banner.
+// Archived terms are view only, so they are searched from scraped data rather than live. The subject index is used to find
+// courses, so at least one subject is required; results are ordered by subject, course number & section.
+func ScrapedSearch(ctx context.Context, query *Query) (*SearchResult, error) {
+	if query.subjects == nil {
+		return nil, fmt.Errorf("scraped searches require a subject")
+	}
+
+	matches := []Course{}
+	for _, subject := range *query.subjects {
+		courses, err := GetSubjectCourses(ctx, query.TermCode(), subject)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, course := range courses {
+			if query.Matches(course) {
+				matches = append(matches, course)
+			}
+		}
+	}
+
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Subject != matches[b].Subject {
+			return matches[a].Subject < matches[b].Subject
+		}
+		if matches[a].CourseNumber != matches[b].CourseNumber {
+			return matches[a].CourseNumber < matches[b].CourseNumber
+		}
+		return matches[a].SequenceNumber < matches[b].SequenceNumber
+	})
+
+	start := min(query.offset, len(matches))
+	end := min(start+query.maxResults, len(matches))
+	return &SearchResult{
+		Success:     true,
+		TotalCount:  len(matches),
+		PageOffset:  query.offset,
+		PageMaxSize: query.maxResults,
+		Data:        matches[start:end],
+	}, nil
+}
+
+// IsTermSearchedScraped checks if searches of the term are served from scraped data, as it is archived (view only).
+// Unlike IsTermArchived, terms that cannot be found are searched live.
+func IsTermSearchedScraped(ctx context.Context, term string) bool {
+	bannerTerm, err := FindBannerTerm(ctx, term)
+	if err != nil || bannerTerm == nil {
+		return false
+	}
+	return bannerTerm.Archived()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// SectionBadge is a special designation of a section (e.g. honors), decoded from its section attributes
+type SectionBadge struct {
+	// A short, friendly name for the designation (e.g. Honors)
+	Name  string
+	Emoji string
+	// The attribute codes indicating this designation, also used to filter searches
+	Codes []string
+	// Matched against attribute descriptions, catching codes not listed above
+	Keyword string
+}
+
+// SectionBadges are the special designations recognized in section attributes
+var SectionBadges = []SectionBadge{
+	{Name: "Honors", Emoji: "🎓", Codes: []string{"HON", "HONR"}, Keyword: "honors"},
+	{Name: "Writing Intensive", Emoji: "✍️", Codes: []string{"LEWR", "WI"}, Keyword: "writing"},
+	{Name: "Service Learning", Emoji: "🤝", Codes: []string{"ZZSL", "SL"}, Keyword: "service learning"},
+}
+
+// FindSectionBadge returns the badge with the given name, case-insensitive
+func FindSectionBadge(name string) (SectionBadge, bool) {
+	return lo.Find(SectionBadges, func(badge SectionBadge) bool {
+		return strings.EqualFold(badge.Name, strings.TrimSpace(name))
+	})
+}
+
+// Matches checks if the attribute code or description indicates this designation
+func (badge SectionBadge) Matches(code string, description string) bool {
+	return lo.Contains(badge.Codes, strings.ToUpper(code)) || strings.Contains(strings.ToLower(description), badge.Keyword)
+}
+
+// String returns the badge as displayed alongside a course (e.g. "🎓 Honors")
+func (badge SectionBadge) String() string {
+	return badge.Emoji + " " + badge.Name
+}
+
+// Badges returns the special designations of the course, in the order of SectionBadges
+func (course Course) Badges() []SectionBadge {
+	return lo.Filter(SectionBadges, func(badge SectionBadge, _ int) bool {
+		return lo.SomeBy(course.SectionAttributes, func(attribute SectionAttribute) bool {
+			return badge.Matches(attribute.Code, attribute.Description)
+		})
+	})
+}
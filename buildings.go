@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// buildingMaps maps building codes (e.g. SP1) to a URL showing the building on a map.
+// It is loaded from the JSON file given by BUILDING_MAPS_FILE, and is empty if not configured.
+var buildingMaps = map[string]string{}
+
+// LoadBuildingMaps loads the building code to map URL lookup table from a JSON object file (e.g. {"SP1": "https://..."})
+func LoadBuildingMaps(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read building maps: %w", err)
+	}
+
+	maps := map[string]string{}
+	err = json.Unmarshal(raw, &maps)
+	if err != nil {
+		return fmt.Errorf("failed to parse building maps: %w", err)
+	}
+
+	// Normalize the building codes, as Banner always uses uppercase
+	buildingMaps = make(map[string]string, len(maps))
+	for code, mapURL := range maps {
+		buildingMaps[strings.ToUpper(strings.TrimSpace(code))] = mapURL
+	}
+
+	log.Debug().Int("count", len(buildingMaps)).Str("path", path).Msg("Loaded building maps")
+	return nil
+}
+
+// BuildingMapURL returns the map URL for the given building code, if one is configured
+func BuildingMapURL(building string) (string, bool) {
+	mapURL, ok := buildingMaps[strings.ToUpper(building)]
+	return mapURL, ok && mapURL != ""
+}
+
+// IndexBuildings records the building codes & names a course meets in, building the index used by the /buildings command
+func IndexBuildings(ctx context.Context, course Course) error {
+	buildings := map[string]interface{}{}
+	for _, meeting := range course.MeetingsFaculty {
+		mt := meeting.MeetingTime
+		if mt.Building == "" || mt.BuildingDescription == "" {
+			continue
+		}
+		buildings[mt.Building] = mt.BuildingDescription
+	}
+
+	if len(buildings) == 0 {
+		return nil
+	}
+
+	return kv.HSet(ctx, BuildingsKey(), buildings).Err()
+}
+
+// GetBuildings returns every building code & name seen while scraping
+func GetBuildings(ctx context.Context) (map[string]string, error) {
+	buildings, err := kv.HGetAll(ctx, BuildingsKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buildings: %w", err)
+	}
+	return buildings, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// MaxChangeLogSize is the maximum number of changes kept per term; the oldest are discarded first
+const MaxChangeLogSize = 20000
+
+// FieldChange is a difference in one meaningful field between two versions of a course
+type FieldChange struct {
+	// The kind of change (e.g. time, location, instructor, seats, waitlist, title, status)
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// CourseChange is a single detected change to a course between two scrapes
+type CourseChange struct {
+	CourseReferenceNumber string    `json:"crn"`
+	Time                  time.Time `json:"time"`
+	FieldChange
+}
+
+func (change CourseChange) MarshalBinary() ([]byte, error) {
+	return json.Marshal(change)
+}
+
+// scheduleSummary describes when a course meets, using the raw meeting fields so malformed times cannot panic
+func scheduleSummary(course Course) string {
+	return strings.Join(lo.Map(course.MeetingsFaculty, func(meeting MeetingTimeResponse, _ int) string {
+		mt := meeting.MeetingTime
+		if mt.BeginTime == "" || mt.EndTime == "" {
+			return "No Time"
+		}
+		return fmt.Sprintf("%s %s-%s", WeekdaysToString(meeting.Days()), mt.BeginTime, mt.EndTime)
+	}), ", ")
+}
+
+// locationSummary describes where a course meets
+func locationSummary(course Course) string {
+	return strings.Join(lo.Map(course.MeetingsFaculty, func(meeting MeetingTimeResponse, _ int) string {
+		return meeting.PlaceString()
+	}), ", ")
+}
+
+// instructorSummary lists the instructors of a course
+func instructorSummary(course Course) string {
+	return strings.Join(lo.Map(course.Faculty, func(faculty FacultyItem, _ int) string {
+		return faculty.DisplayName
+	}), ", ")
+}
+
+// Diff compares the meaningful fields (schedule, location, instructors, seats, waitlist, title & status) of the course with a newer version of it.
+// Volatile or internal fields (e.g. IDs, linked sections, cross-list counts) are ignored.
+func (course Course) Diff(other Course) []FieldChange {
+	changes := []FieldChange{}
+	compare := func(field string, oldValue string, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	compare("time", scheduleSummary(course), scheduleSummary(other))
+	compare("location", locationSummary(course), locationSummary(other))
+	compare("instructor", instructorSummary(course), instructorSummary(other))
+	compare("seats", fmt.Sprintf("%d/%d", course.Enrollment, course.MaximumEnrollment), fmt.Sprintf("%d/%d", other.Enrollment, other.MaximumEnrollment))
+	compare("waitlist", fmt.Sprintf("%d/%d", course.WaitCount, course.WaitCapacity), fmt.Sprintf("%d/%d", other.WaitCount, other.WaitCapacity))
+	compare("title", course.CourseTitle, other.CourseTitle)
+	compare("status", lo.Ternary(course.OpenSection, "open", "closed"), lo.Ternary(other.OpenSection, "open", "closed"))
+
+	return changes
+}
+
+// Equal returns true if the course has no meaningful differences from the other, as compared by Diff
+func (course Course) Equal(other Course) bool {
+	return len(course.Diff(other)) == 0
+}
+
+// DiffCourses compares two versions of the same course, returning the changes from old to new
+func DiffCourses(old Course, new Course, now time.Time) []CourseChange {
+	return lo.Map(old.Diff(new), func(change FieldChange, _ int) CourseChange {
+		return CourseChange{
+			CourseReferenceNumber: new.CourseReferenceNumber,
+			Time:                  now,
+			FieldChange:           change,
+		}
+	})
+}
+
+// RecordChanges appends the changes to the term's change log (changes:<term>), oldest first
+func RecordChanges(ctx context.Context, term string, changes []CourseChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	key := ChangesKey(term)
+	values := lo.Map(changes, func(change CourseChange, _ int) interface{} {
+		return change
+	})
+
+	pipe := kv.Pipeline()
+	pipe.RPush(ctx, key, values...)
+	pipe.LTrim(ctx, key, -MaxChangeLogSize, -1)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to record changes: %w", err)
+	}
+
+	return nil
+}
+
+// GetCourseHistory returns the recorded changes for a course in chronological order
+func GetCourseHistory(ctx context.Context, term string, crn int) ([]CourseChange, error) {
+	raw, err := kv.LRange(ctx, ChangesKey(term), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes: %w", err)
+	}
+
+	target := strconv.Itoa(crn)
+	history := []CourseChange{}
+	for _, entry := range raw {
+		var change CourseChange
+		err := json.Unmarshal([]byte(entry), &change)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal change: %w", err)
+		}
+
+		if change.CourseReferenceNumber == target {
+			history = append(history, change)
+		}
+	}
+
+	return history, nil
+}
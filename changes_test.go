@@ -0,0 +1,110 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// changeTestCourse returns a course meeting in person on Monday & Wednesday mornings
+func changeTestCourse() Course {
+	meeting := MeetingTimeResponse{}
+	meeting.MeetingTime.BeginTime = "0930"
+	meeting.MeetingTime.EndTime = "1045"
+	meeting.MeetingTime.Monday = true
+	meeting.MeetingTime.Wednesday = true
+	meeting.MeetingTime.CampusDescription = "Main Campus"
+	meeting.MeetingTime.BuildingDescription = "Science Building"
+	meeting.MeetingTime.Building = "SB"
+	meeting.MeetingTime.Room = "2.01"
+
+	return Course{
+		Id:                    1,
+		CourseReferenceNumber: "12345",
+		CourseTitle:           "Data Structures",
+		Enrollment:            30,
+		MaximumEnrollment:     40,
+		WaitCount:             0,
+		WaitCapacity:          10,
+		OpenSection:           true,
+		Faculty:               []FacultyItem{{DisplayName: "Doe, Jane"}},
+		MeetingsFaculty:       []MeetingTimeResponse{meeting},
+	}
+}
+
+func TestCourseDiff(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(course *Course)
+		want   []FieldChange
+	}{
+		{"unchanged", func(course *Course) {}, []FieldChange{}},
+		{"ignored fields", func(course *Course) {
+			course.Id = 2
+			course.IsSectionLinked = true
+		}, []FieldChange{}},
+		{"time", func(course *Course) {
+			course.MeetingsFaculty[0].MeetingTime.BeginTime = "1000"
+		}, []FieldChange{{"time", "MW 0930-1045", "MW 1000-1045"}}},
+		{"days", func(course *Course) {
+			course.MeetingsFaculty[0].MeetingTime.Friday = true
+		}, []FieldChange{{"time", "MW 0930-1045", "MWF 0930-1045"}}},
+		{"location", func(course *Course) {
+			course.MeetingsFaculty[0].MeetingTime.Room = "3.02"
+		}, []FieldChange{{"location", "Main Campus | Science Building | SB 2.01", "Main Campus | Science Building | SB 3.02"}}},
+		{"instructor", func(course *Course) {
+			course.Faculty = []FacultyItem{{DisplayName: "Smith, John"}}
+		}, []FieldChange{{"instructor", "Doe, Jane", "Smith, John"}}},
+		{"instructor removed", func(course *Course) {
+			course.Faculty = nil
+		}, []FieldChange{{"instructor", "Doe, Jane", ""}}},
+		{"seats", func(course *Course) {
+			course.Enrollment = 31
+		}, []FieldChange{{"seats", "30/40", "31/40"}}},
+		{"waitlist", func(course *Course) {
+			course.WaitCount = 2
+		}, []FieldChange{{"waitlist", "0/10", "2/10"}}},
+		{"title", func(course *Course) {
+			course.CourseTitle = "Algorithms"
+		}, []FieldChange{{"title", "Data Structures", "Algorithms"}}},
+		{"status", func(course *Course) {
+			course.Enrollment = 40
+			course.OpenSection = false
+		}, []FieldChange{{"seats", "30/40", "40/40"}, {"status", "open", "closed"}}},
+		{"meeting time removed", func(course *Course) {
+			course.MeetingsFaculty[0].MeetingTime.BeginTime = ""
+			course.MeetingsFaculty[0].MeetingTime.EndTime = ""
+		}, []FieldChange{{"time", "MW 0930-1045", "No Time"}}},
+	}
+
+	for _, c := range cases {
+		old := changeTestCourse()
+		updated := changeTestCourse()
+		c.modify(&updated)
+
+		got := old.Diff(updated)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: Diff() = %+v, want %+v", c.name, got, c.want)
+		}
+		if equal := old.Equal(updated); equal != (len(c.want) == 0) {
+			t.Errorf("%s: Equal() = %t, want %t", c.name, equal, len(c.want) == 0)
+		}
+	}
+}
+
+func TestDiffCourses(t *testing.T) {
+	old := changeTestCourse()
+	updated := changeTestCourse()
+	updated.Enrollment = 35
+	updated.CourseTitle = "Advanced Data Structures"
+	now := time.Date(2024, time.January, 16, 9, 0, 0, 0, time.UTC)
+
+	want := []CourseChange{
+		{CourseReferenceNumber: "12345", Time: now, FieldChange: FieldChange{"seats", "30/40", "35/40"}},
+		{CourseReferenceNumber: "12345", Time: now, FieldChange: FieldChange{"title", "Data Structures", "Advanced Data Structures"}},
+	}
+
+	if got := DiffCourses(old, updated, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffCourses() = %+v, want %+v", got, want)
+	}
+}
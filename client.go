@@ -0,0 +1,54 @@
+package main
+
+import "context"
+
+// BannerClient is the part of the Banner API used by the command handlers.
+// Handlers call Banner through bannerClient, so a fake can be substituted (e.g. in tests).
+type BannerClient interface {
+	Search(ctx context.Context, query *Query, sort string, sortDescending bool) (*SearchResult, error)
+	CachedSearch(ctx context.Context, query *Query, sort string, sortDescending bool) (*SearchResult, error)
+	ScrapedSearch(ctx context.Context, query *Query) (*SearchResult, error)
+	GetTerms(ctx context.Context, search string, page int, max int) (*TermsResult, error)
+	GetSubjects(ctx context.Context, search string, term string, offset int, max int) ([]Pair, error)
+	GetCourse(ctx context.Context, crn string) (*Course, error)
+	FindCourseByCRN(ctx context.Context, term string, crn string) (*Course, error)
+	GetCourseMeetingTime(ctx context.Context, term int, crn int) ([]MeetingTimeResponse, error)
+}
+
+// bannerClient is the client used by the command handlers
+var bannerClient BannerClient = liveClient{}
+
+// liveClient is the BannerClient backed by the real Banner system & scraped data
+type liveClient struct{}
+
+func (liveClient) Search(ctx context.Context, query *Query, sort string, sortDescending bool) (*SearchResult, error) {
+	return Search(ctx, query, sort, sortDescending)
+}
+
+func (liveClient) CachedSearch(ctx context.Context, query *Query, sort string, sortDescending bool) (*SearchResult, error) {
+	return CachedSearch(ctx, query, sort, sortDescending)
+}
+
+func (liveClient) ScrapedSearch(ctx context.Context, query *Query) (*SearchResult, error) {
+	return ScrapedSearch(ctx, query)
+}
+
+func (liveClient) GetTerms(ctx context.Context, search string, page int, max int) (*TermsResult, error) {
+	return GetTerms(ctx, search, page, max)
+}
+
+func (liveClient) GetSubjects(ctx context.Context, search string, term string, offset int, max int) ([]Pair, error) {
+	return GetSubjects(ctx, search, term, offset, max)
+}
+
+func (liveClient) GetCourse(ctx context.Context, crn string) (*Course, error) {
+	return GetCourse(ctx, crn)
+}
+
+func (liveClient) FindCourseByCRN(ctx context.Context, term string, crn string) (*Course, error) {
+	return FindCourseByCRN(ctx, term, crn)
+}
+
+func (liveClient) GetCourseMeetingTime(ctx context.Context, term int, crn int) ([]MeetingTimeResponse, error) {
+	return GetCourseMeetingTime(ctx, term, crn)
+}
@@ -1,27 +1,77 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
-	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/bwmarrin/discordgo"
-	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/samber/lo"
 )
 
 var (
-	commandDefinitions = []*discordgo.ApplicationCommand{TermCommandDefinition, TimeCommandDefinition, SearchCommandDefinition, IcsCommandDefinition}
-	commandHandlers    = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate) error{
-		TimeCommandDefinition.Name:   TimeCommandHandler,
-		TermCommandDefinition.Name:   TermCommandHandler,
-		SearchCommandDefinition.Name: SearchCommandHandler,
-		IcsCommandDefinition.Name:    IcsCommandHandler,
+	commandDefinitions = []*discordgo.ApplicationCommand{TermCommandDefinition, TimeCommandDefinition, SearchCommandDefinition, IcsCommandDefinition, BuildingsCommandDefinition, RescrapeCommandDefinition, ConfigCommandDefinition, HistoryCommandDefinition, SelfTestCommandDefinition, FeedbackCommandDefinition, SeatsCommandDefinition, CoverageCommandDefinition, ResearchCommandDefinition, ConflictsCommandDefinition, ScheduleCommandDefinition, SetTermCommandDefinition, WhichTermCommandDefinition, RawCommandDefinition, EnrollStatsCommandDefinition}
+	commandHandlers    = map[string]func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error{
+		TimeCommandDefinition.Name:        TimeCommandHandler,
+		TermCommandDefinition.Name:        TermCommandHandler,
+		SearchCommandDefinition.Name:      SearchCommandHandler,
+		IcsCommandDefinition.Name:         IcsCommandHandler,
+		BuildingsCommandDefinition.Name:   BuildingsCommandHandler,
+		RescrapeCommandDefinition.Name:    RescrapeCommandHandler,
+		ConfigCommandDefinition.Name:      ConfigCommandHandler,
+		HistoryCommandDefinition.Name:     HistoryCommandHandler,
+		SelfTestCommandDefinition.Name:    SelfTestCommandHandler,
+		FeedbackCommandDefinition.Name:    FeedbackCommandHandler,
+		SeatsCommandDefinition.Name:       SeatsCommandHandler,
+		CoverageCommandDefinition.Name:    CoverageCommandHandler,
+		ResearchCommandDefinition.Name:    ResearchCommandHandler,
+		ConflictsCommandDefinition.Name:   ConflictsCommandHandler,
+		ScheduleCommandDefinition.Name:    ScheduleCommandHandler,
+		SetTermCommandDefinition.Name:     SetTermCommandHandler,
+		WhichTermCommandDefinition.Name:   WhichTermCommandHandler,
+		RawCommandDefinition.Name:         RawCommandHandler,
+		EnrollStatsCommandDefinition.Name: EnrollStatsCommandHandler,
 	}
+	// autocompleteHandlers respond to autocomplete interactions for commands with autocompleted options
+	autocompleteHandlers = map[string]func(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error{
+		SearchCommandDefinition.Name: SearchAutocompleteHandler,
+	}
+	// privilegedCommands are only usable by admins, checked before the handler is invoked
+	privilegedCommands = map[string]bool{
+		RescrapeCommandDefinition.Name: true,
+		SelfTestCommandDefinition.Name: true,
+		RawCommandDefinition.Name:      true,
+	}
+)
+
+// DaysFilterPageSize is the number of results requested when filtering by days or schedule type, as the filters are applied afterwards
+const DaysFilterPageSize = 50
+
+// FieldsPerSearchResult is the number of embed fields each /search result uses
+const FieldsPerSearchResult = 3
+
+// MaxSearchResults is the maximum number of results /search will show, limited by the number of embed fields each result uses
+const MaxSearchResults = 8
+
+// ScheduleTypes are the common schedule types offered by the /search scheduletype filter, as described by Banner
+var ScheduleTypes = []string{"Lecture", "Laboratory", "Seminar", "Independent Study", "Internship", "Thesis", "Dissertation", "Self-paced"}
+
+// MaxCompactSearchResults is the maximum number of results /search will show with the compact layout, one line each
+const MaxCompactSearchResults = 25
+
+// The /search result layouts; detailed uses several fields per course, while compact lists one course per line
+const (
+	SearchLayoutDetailed = "detailed"
+	SearchLayoutCompact  = "compact"
 )
 
 var SearchCommandDefinition = &discordgo.ApplicationCommand{
@@ -40,20 +90,28 @@ var SearchCommandDefinition = &discordgo.ApplicationCommand{
 		{
 			Type:        discordgo.ApplicationCommandOptionString,
 			Name:        "code",
-			MinLength:   GetIntPointer(4),
-			Description: "Course Code (e.g. 3743, 3000-3999, 3xxx, 3000-)",
+			MinLength:   GetIntPointer(2),
+			Description: "Course Code (e.g. 3743, 3000-3999, 3xxx, 3xx, 3000-, -3999)",
 			Required:    false,
 		},
 		{
 			Type:        discordgo.ApplicationCommandOptionInteger,
 			Name:        "max",
-			Description: "Maximum number of results",
+			Description: fmt.Sprintf("Maximum number of results (1-%d, or 1-%d when compact)", MaxSearchResults, MaxCompactSearchResults),
 			Required:    false,
+			MinValue:    GetFloatPointer(1),
+			MaxValue:    MaxCompactSearchResults,
 		},
 		{
 			Type:        discordgo.ApplicationCommandOptionString,
 			Name:        "keywords",
-			Description: "Keywords in Title or Description (space separated)",
+			Description: "Keywords in Title or Description (space separated, \"quote\" phrases)",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "phrase",
+			Description: "Exact phrase in Title or Description",
+			Required:    false,
 		},
 		{
 			Type:         discordgo.ApplicationCommandOptionString,
@@ -65,136 +123,475 @@ var SearchCommandDefinition = &discordgo.ApplicationCommand{
 		{
 			Type:         discordgo.ApplicationCommandOptionString,
 			Name:         "subject",
-			Description:  "Subject (e.g. Computer Science/CS, Mathematics/MAT)",
+			Description:  "Subject, comma separated for multiple (e.g. CS, MAT)",
 			Required:     false,
 			Autocomplete: true,
 		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "refresh",
+			Description: "Skip recently cached results",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "crn",
+			Description: "Course Reference Number, shows only that section (other filters are ignored)",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "days",
+			Description: "Only classes meeting on these days (e.g. MW, TR, MTWR for no Fridays)",
+			Required:    false,
+			MaxLength:   7,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "scheduletype",
+			Description: "Only sections of this type (e.g. Lecture, Laboratory)",
+			Required:    false,
+			Choices: lo.Map(ScheduleTypes, func(scheduleType string, _ int) *discordgo.ApplicationCommandOptionChoice {
+				return &discordgo.ApplicationCommandOptionChoice{Name: scheduleType, Value: scheduleType}
+			}),
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "designation",
+			Description: "Only special sections (e.g. Honors)",
+			Required:    false,
+			Choices: lo.Map(SectionBadges, func(badge SectionBadge, _ int) *discordgo.ApplicationCommandOptionChoice {
+				return &discordgo.ApplicationCommandOptionChoice{Name: badge.Name, Value: badge.Name}
+			}),
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "page",
+			Description: "Page of results to show",
+			Required:    false,
+			MinValue:    GetFloatPointer(1),
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "layout",
+			Description: "How results are shown, detailed by default",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Detailed", Value: SearchLayoutDetailed},
+				{Name: "Compact (one line per course)", Value: SearchLayoutCompact},
+			},
+		},
 	},
 }
 
-func SearchCommandHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
-	data := interaction.ApplicationCommandData()
-	query := NewQuery().Credits(3, 6)
+// FieldsPerMeeting is the number of embed fields used to display each meeting in /time
+const FieldsPerMeeting = 3
 
-	for _, option := range data.Options {
-		switch option.Name {
-		case "title":
-			query.Title(option.StringValue())
-		case "code":
-			var (
-				low  = -1
-				high = -1
-			)
-			var err error
-			valueRaw := strings.TrimSpace(option.StringValue())
+// MaxScheduleCourses is the maximum number of CRNs accepted by the schedule planning commands
+const MaxScheduleCourses = 10
 
-			// Partially/fully specified range
-			if strings.Contains(valueRaw, "-") {
-				match := regexp.MustCompile(`(\d{1,4})-(\d{1,4})?`).FindSubmatch([]byte(valueRaw))
+// ParseCRNs splits a comma or space separated list of CRNs, dropping repeats
+func ParseCRNs(raw string) ([]string, error) {
+	crns := lo.Uniq(strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}))
 
-				if match == nil {
-					return fmt.Errorf("invalid range format: %s", valueRaw)
-				}
+	for _, crn := range crns {
+		if _, err := strconv.Atoi(crn); err != nil {
+			return nil, NewUserError("Invalid CRN: %s", crn)
+		}
+	}
 
-				// If not 2 or 3 matches, it's invalid
-				if len(match) != 3 && len(match) != 4 {
-					return fmt.Errorf("invalid range format: %s", match[0])
-				}
+	if len(crns) == 0 {
+		return nil, NewUserError("No CRNs given")
+	}
 
-				low, err = strconv.Atoi(string(match[1]))
-				if err != nil {
-					return errors.Wrap(err, "error parsing course code (low)")
-				}
+	if len(crns) > MaxScheduleCourses {
+		return nil, NewUserError("Too many CRNs, at most %d are allowed (%d)", MaxScheduleCourses, len(crns))
+	}
 
-				// If there's not a high value, set it to max (open ended)
-				if len(match) == 2 || len(match[2]) == 0 {
-					high = 9999
-				} else {
-					high, err = strconv.Atoi(string(match[2]))
-					if err != nil {
-						return errors.Wrap(err, "error parsing course code (high)")
-					}
-				}
-			}
+	return crns, nil
+}
 
-			// #xxx, ##xx, ###x format (34xx -> 3400-3499)
-			if strings.Contains(valueRaw, "x") {
-				if len(valueRaw) != 4 {
-					return fmt.Errorf("code range format invalid: must be 1 or more digits followed by x's (%s)", valueRaw)
-				}
+// FetchCourses looks up each CRN within the term, failing with a user error naming any that could not be found
+func FetchCourses(ctx context.Context, term string, crns []string) ([]Course, error) {
+	courses := []Course{}
+	missing := []string{}
+	for _, crn := range crns {
+		course, err := bannerClient.FindCourseByCRN(ctx, term, crn)
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Str("crn", crn).Msg("Course not found")
+			missing = append(missing, crn)
+			continue
+		}
+		courses = append(courses, *course)
+	}
 
-				match := regexp.MustCompile(`\d{1,}([xX]{1,3})`).Match([]byte(valueRaw))
-				if !match {
-					return fmt.Errorf("code range format invalid: must be 1 or more digits followed by x's (%s)", valueRaw)
-				}
+	if len(missing) > 0 {
+		return nil, NewUserError("No course found with CRN %s", strings.Join(missing, ", "))
+	}
 
-				// Replace x's with 0's
-				low, err = strconv.Atoi(strings.Replace(valueRaw, "x", "0", -1))
-				if err != nil {
-					return errors.Wrap(err, "error parsing implied course code (low)")
-				}
+	return courses, nil
+}
 
-				// Replace x's with 9's
-				high, err = strconv.Atoi(strings.Replace(valueRaw, "x", "9", -1))
-				if err != nil {
-					return errors.Wrap(err, "error parsing implied course code (high)")
-				}
-			} else if len(valueRaw) == 4 {
-				// 4 digit code
-				low, err = strconv.Atoi(valueRaw)
-				if err != nil {
-					return errors.Wrap(err, "error parsing course code")
-				}
+// CourseMeetingTimes are the meetings of a section within a term, as found by LookupMeetingTimes
+type CourseMeetingTimes struct {
+	Term     Term
+	CRN      int
+	Meetings []MeetingTimeResponse
+}
+
+// Scheduled returns true if any of the meetings has a start & end time
+func (m *CourseMeetingTimes) Scheduled() bool {
+	return lo.SomeBy(m.Meetings, func(meeting MeetingTimeResponse) bool {
+		_, _, ok := meetingClock(meeting)
+		return ok
+	})
+}
+
+// LookupMeetingTimes retrieves the meetings of a section, distinguishing a CRN that does not exist in the term from one without meetings.
+// Banner answers both with no meetings, so the CRN is looked up when none are returned, failing with a CourseNotFoundError if it doesn't exist.
+// A CRN scraped in another term (e.g. once the default term has moved on) is looked up in that term instead, given by the result's Term.
+func LookupMeetingTimes(ctx context.Context, term Term, crn int) (*CourseMeetingTimes, error) {
+	meetings, err := bannerClient.GetCourseMeetingTime(ctx, term.Code(), crn)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(meetings) == 0 {
+		_, err := bannerClient.FindCourseByCRN(ctx, term.ToString(), strconv.Itoa(crn))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Int("crn", crn).Msg("Course without meetings not found")
 
-				high = low
+			scraped, scrapedErr := bannerClient.GetCourse(ctx, strconv.Itoa(crn))
+			if scrapedErr == nil && scraped.Term != term.ToString() && IsValidTermCode(scraped.Term) {
+				log.Ctx(ctx).Info().Int("crn", crn).Str("requested", term.ToString()).Str("actual", scraped.Term).Msg("CRN belongs to another term, looking up its meetings there")
+				return LookupMeetingTimes(ctx, ParseTerm(scraped.Term), crn)
 			}
 
-			if low == -1 || high == -1 {
-				return fmt.Errorf("course code range invalid (%s)", valueRaw)
+			return nil, &CourseNotFoundError{Term: term.ToString(), CRN: strconv.Itoa(crn)}
+		}
+	}
+
+	return &CourseMeetingTimes{Term: term, CRN: crn, Meetings: meetings}, nil
+}
+
+// ParseSubjects splits a comma separated list of subject codes, normalizing case and dropping empty or repeated codes
+func ParseSubjects(raw string) []string {
+	subjects := lo.FilterMap(strings.Split(raw, ","), func(subject string, _ int) (string, bool) {
+		subject = strings.ToUpper(strings.TrimSpace(subject))
+		return subject, subject != ""
+	})
+	return lo.Uniq(subjects)
+}
+
+// SearchAutocompleteHandler suggests values for the focused /search option.
+// Subjects are completed one at a time, keeping any already entered (e.g. "CS,MA" suggests "CS,MAT").
+func SearchAutocompleteHandler(ctx context.Context, session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+
+	for _, option := range interaction.ApplicationCommandData().Options {
+		if !option.Focused || option.Name != "subject" {
+			continue
+		}
+
+		raw := option.StringValue()
+		prefix := ""
+		partial := raw
+		if index := strings.LastIndex(raw, ","); index != -1 {
+			prefix = raw[:index+1]
+			partial = raw[index+1:]
+		}
+		entered := ParseSubjects(prefix)
+
+		subjects, err := bannerClient.GetSubjects(ctx, strings.TrimSpace(partial), Default(time.Now()).ToString(), 1, 25)
+		if err != nil {
+			return err
+		}
+
+		for _, subject := range subjects {
+			if lo.Contains(entered, subject.Code) {
+				continue
 			}
 
-			if low > high {
-				return fmt.Errorf("course code range is invalid: low is greater than high (%d > %d)", low, high)
+			value := strings.Join(append(entered, subject.Code), ",")
+			if len(value) > 100 {
+				continue
 			}
 
-			if low < 1000 || high < 1000 || low > 9999 || high > 9999 {
-				return fmt.Errorf("course code range is invalid: must be 1000-9999 (%d-%d)", low, high)
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  lo.Substring(fmt.Sprintf("%s (%s)", value, subject.Description), 0, 100),
+				Value: value,
+			})
+		}
+	}
+
+	return session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
+
+func SearchCommandHandler(ctx context.Context, session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
+	return RunSearch(ctx, session, interaction, interaction.ApplicationCommandData().Options, true)
+}
+
+// RunSearch performs a /search with the given options, responding to the interaction.
+// Searches are remembered for users that have opted in when record is set.
+func RunSearch(ctx context.Context, session *discordgo.Session, interaction *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption, record bool) error {
+	term := ResolveTerm(ctx, interaction)
+	query := NewQuery().Term(term.ToString()).Credits(3, 6)
+	refresh := false
+	crn := ""
+	page := 1
+	maxResults := 0
+	layout := SearchLayoutDetailed
+	scheduleType := ""
+	var days map[time.Weekday]bool
+
+	for _, option := range options {
+		switch option.Name {
+		case "title":
+			if title := strings.TrimSpace(option.StringValue()); title != "" {
+				query.Title(title)
+			}
+		case "code":
+			low, high, err := ParseCourseCodeRange(option.StringValue())
+			if err != nil {
+				return err
 			}
 
 			query.CourseNumbers(low, high)
 		case "keywords":
-			query.Keywords(
-				strings.Split(option.StringValue(), " "),
-			)
+			keywords := ParseKeywords(option.StringValue())
+			if len(keywords) > 0 {
+				query.Keywords(keywords)
+			}
 		case "max":
-			query.MaxResults(
-				min(8, int(option.IntValue())),
-			)
+			maxResults = int(option.IntValue())
+			if maxResults < 1 {
+				return NewUserError("max must be at least 1 (%d)", maxResults)
+			}
+		case "subject":
+			subjects := ParseSubjects(option.StringValue())
+			if len(subjects) > 0 {
+				query.Subjects(subjects)
+			}
+		case "phrase":
+			if phrase := strings.TrimSpace(option.StringValue()); phrase != "" {
+				query.KeywordExact(phrase)
+			}
+		case "refresh":
+			refresh = option.BoolValue()
+		case "crn":
+			crn = strconv.FormatInt(option.IntValue(), 10)
+		case "days":
+			var err error
+			days, err = ParseDays(option.StringValue())
+			if err != nil {
+				return err
+			}
+		case "page":
+			page = int(option.IntValue())
+		case "scheduletype":
+			scheduleType = strings.TrimSpace(option.StringValue())
+		case "layout":
+			layout = option.StringValue()
+		case "designation":
+			badge, ok := FindSectionBadge(option.StringValue())
+			if !ok {
+				return NewUserError("Unknown designation: %s", option.StringValue())
+			}
+
+			query.Attributes(badge.Codes)
 		}
 	}
 
-	courses, err := Search(query, "", false)
-	if err != nil {
-		session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "Error searching for courses",
-			},
+	// Only request as many results as can be shown, so pages line up with what is displayed
+	pageSize := min(MaxSearchResults, maxEmbedFields/FieldsPerSearchResult)
+	if layout == SearchLayoutCompact {
+		pageSize = MaxCompactSearchResults
+	}
+	if maxResults > 0 {
+		pageSize = min(pageSize, maxResults)
+	}
+	query.MaxResults(pageSize)
+
+	// Banner cannot reliably filter by days or schedule type, so more results are requested and filtered afterwards
+	filters := []func(course Course) bool{}
+	if days != nil {
+		filters = append(filters, func(course Course) bool {
+			return MeetsOnlyOn(course, days)
+		})
+	}
+	if scheduleType != "" {
+		filters = append(filters, func(course Course) bool {
+			return strings.EqualFold(course.ScheduleTypeDescription, scheduleType)
 		})
+	}
+
+	if page > 1 {
+		if len(filters) > 0 {
+			return NewUserError("`page` cannot be combined with `days` or `scheduletype`")
+		}
+		query.Offset((page - 1) * query.maxResults)
+	}
+
+	limit := query.maxResults
+	if len(filters) > 0 {
+		query.MaxResults(DaysFilterPageSize)
+	}
+
+	// Fall back to the guild's default subject
+	if query.subjects == nil {
+		config, err := GetGuildConfig(ctx, interaction.GuildID)
+		if err != nil {
+			return err
+		}
+
+		if config.DefaultSubject != "" {
+			query.Subject(config.DefaultSubject)
+		}
+	}
+
+	// Unfiltered searches return a huge number of results, so require at least one substantive filter
+	if crn == "" && query.subjects == nil && query.title == nil && query.keywords == nil && query.keywordExact == nil && query.courseNumberRange == nil {
+		return NewUserError("Please narrow your search with at least one of: `subject`, `code`, `title`, `keywords`, `phrase`, or `crn`.\nFor example: `/search subject:CS code:3xxx`")
+	}
+
+	if record {
+		err := RecordSearch(ctx, GetUser(interaction).ID, RecentSearch{Description: query.String(), Options: options})
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("Failed to record recent search")
+		}
+	}
+
+	search := bannerClient.CachedSearch
+	if refresh {
+		search = bannerClient.Search
+	}
+
+	// Archived terms are view only, so they are searched from scraped data (found by subject) instead of live
+	archived := IsTermSearchedScraped(ctx, term.ToString())
+	if archived {
+		if crn == "" && query.subjects == nil {
+			return NewUserError("%s is archived, so only previously scraped courses can be searched. Please include a `subject`.", term.HumanName())
+		}
+
+		search = func(ctx context.Context, query *Query, _ string, _ bool) (*SearchResult, error) {
+			return bannerClient.ScrapedSearch(ctx, query)
+		}
+	}
+
+	// Searching may take multiple round-trips to Banner, so defer the response
+	err := DeferResponse(ctx, session, interaction.Interaction)
+	if err != nil {
 		return err
 	}
 
+	var courses *SearchResult
+	if crn != "" {
+		// A CRN identifies a single section, so the other filters are bypassed
+		find := bannerClient.FindCourseByCRN
+		if archived {
+			find = func(ctx context.Context, term string, crn string) (*Course, error) {
+				course, err := bannerClient.GetCourse(ctx, crn)
+				if err == nil && course.Term != term {
+					return nil, fmt.Errorf("course not found in term %s", term)
+				}
+				return course, err
+			}
+		}
+
+		course, err := find(ctx, term.ToString(), crn)
+		if err != nil {
+			return NewUserError("No course found with CRN %s", crn)
+		}
+		courses = &SearchResult{Success: true, TotalCount: 1, Data: []Course{*course}}
+	} else {
+		courses, err = search(ctx, query, "", false)
+		if err != nil {
+			return RespondErrorWithLevel(ctx, session, interaction.Interaction, ErrorLevelUpstream, "Error searching for courses", err)
+		}
+
+		if page > 1 && len(courses.Data) == 0 && courses.TotalCount > 0 {
+			return NewUserError("Page %d is past the last page (%d)", page, courses.PageCount())
+		}
+
+		if len(filters) > 0 {
+			filtered := lo.Filter(courses.Data, func(course Course, _ int) bool {
+				return lo.EveryBy(filters, func(filter func(course Course) bool) bool {
+					return filter(course)
+				})
+			})
+
+			// Copy, as the result may be shared with the search cache
+			courses = &SearchResult{Success: courses.Success, TotalCount: len(filtered), Data: filtered[:min(limit, len(filtered))]}
+		}
+	}
+
 	fetch_time := time.Now()
 	fields := []*discordgo.MessageEmbedField{}
+	lines := []string{}
+
+	// Only show whole courses, as each uses several fields (or a line, when compact)
+	shown := min(len(courses.Data), limit)
+	for _, course := range courses.Data[:shown] {
+		if layout == SearchLayoutCompact {
+			lines = append(lines, compactSearchLine(course))
+			continue
+		}
 
-	for _, course := range courses.Data {
-		displayName := course.Faculty[0].DisplayName
 		categoryLink := fmt.Sprintf("[%s](https://catalog.utsa.edu/undergraduate/coursedescriptions/%s/)", course.Subject, strings.ToLower(course.Subject))
 		classLink := fmt.Sprintf("[%s-%s](https://catalog.utsa.edu/search/?P=%s%%20%s)", course.CourseNumber, course.SequenceNumber, course.Subject, course.CourseNumber)
-		professorLink := fmt.Sprintf("[%s](https://www.ratemyprofessors.com/search/professors/1516?q=%s)", displayName, url.QueryEscape(displayName))
+
+		// Sections without an assigned instructor have no one to link to
+		professorLink := "TBA"
+		displayName := ""
+		if len(course.Faculty) > 0 {
+			displayName = course.Faculty[0].DisplayName
+			professorLink = fmt.Sprintf("[%s](https://www.ratemyprofessors.com/search/professors/1516?q=%s)", EscapeMarkdown(displayName), url.QueryEscape(displayName))
+		}
+
+		// Link directly to the professor's page with their rating, if available
+		if ratingsEnabled && displayName != "" {
+			rating, err := GetProfessorRating(ctx, displayName)
+			if err != nil {
+				log.Ctx(ctx).Warn().Err(err).Str("instructor", displayName).Msg("Failed to get professor rating")
+			} else if rating.Found {
+				professorLink = fmt.Sprintf("[%s](%s)", EscapeMarkdown(displayName), rating.URL())
+				if rating.NumRatings > 0 {
+					professorLink += fmt.Sprintf(" (★ %.1f, difficulty %.1f)", rating.Rating, rating.Difficulty)
+				}
+			}
+		}
 
 		identifierText := fmt.Sprintf("%s %s (CRN %s)\n%s", categoryLink, classLink, course.CourseReferenceNumber, professorLink)
-		meetings := course.MeetingsFaculty[0]
+
+		// Sections meeting in several places list each place, as the first meeting's place alone would mislead
+		meetingText := "No Time"
+		if len(course.MeetingsFaculty) > 0 {
+			meetings := course.MeetingsFaculty[0]
+			meetingText = meetings.String()
+			if locations := course.Locations(); len(locations) > 1 {
+				timeText := "No Time"
+				if _, _, ok := meetingClock(meetings); ok {
+					timeText = meetings.TimeString()
+				}
+				meetingText = fmt.Sprintf("%s\n%s", timeText, strings.Join(locations, "; "))
+			}
+		}
+
+		nameText := fmt.Sprintf("%s %s (%s cr)", course.StatusEmoji(), EscapeMarkdown(course.CourseTitle), course.CreditString())
+		badges := lo.Map(course.Badges(), func(badge SectionBadge, _ int) string { return badge.String() })
+		if course.ScheduleTypeDescription != "" {
+			badges = append([]string{"`" + course.ScheduleTypeDescription + "`"}, badges...)
+		}
+		if len(badges) > 0 {
+			nameText += "\n" + strings.Join(badges, ", ")
+		}
 
 		fields = append(fields, &discordgo.MessageEmbedField{
 			Name:   "Identifier",
@@ -202,11 +599,11 @@ func SearchCommandHandler(session *discordgo.Session, interaction *discordgo.Int
 			Inline: true,
 		}, &discordgo.MessageEmbedField{
 			Name:   "Name",
-			Value:  course.CourseTitle,
+			Value:  nameText,
 			Inline: true,
 		}, &discordgo.MessageEmbedField{
 			Name:   "Meeting Time",
-			Value:  meetings.String(),
+			Value:  meetingText,
 			Inline: true,
 		},
 		)
@@ -218,22 +615,61 @@ func SearchCommandHandler(session *discordgo.Session, interaction *discordgo.Int
 		color = 0xFF6500
 	}
 
-	err = session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{
-				{
-					Footer:      GetFetchedFooter(fetch_time),
-					Description: p.Sprintf("%d Class%s", courses.TotalCount, Plurale(courses.TotalCount)),
-					Fields:      fields[:min(25, len(fields))],
-					Color:       color,
-				},
+	// Archived terms are view only, and Banner may return incomplete or no results for them
+	description := p.Sprintf(msgClassCount, courses.TotalCount)
+	if archived {
+		description += fmt.Sprintf("\n⚠️ %s is archived (view only), so results are from previously scraped data and may be incomplete.", term.HumanName())
+	}
+	if len(lines) > 0 {
+		// Stay well within the description limit, should many long lines be shown
+		for len(lines) > 1 && len(strings.Join(lines, "\n")) > 3500 {
+			lines = lines[:len(lines)-1]
+		}
+		shown = len(lines)
+		description += "\n\n" + strings.Join(lines, "\n")
+	}
+
+	footer := GetFetchedFooter(fetch_time)
+	if crn == "" && len(filters) == 0 && courses.PageCount() > 1 {
+		footer.Text = fmt.Sprintf("Page %d of %d, use page or refine your search for more • %s", courses.Page(), courses.PageCount(), footer.Text)
+	} else if shown < courses.TotalCount {
+		footer.Text = fmt.Sprintf("Showing %d of %d, refine your search for more • %s", shown, courses.TotalCount, footer.Text)
+	}
+
+	return Respond(ctx, session, interaction.Interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Footer:      footer,
+				Description: description,
+				Fields:      fields,
+				Color:       color,
 			},
-			AllowedMentions: &discordgo.MessageAllowedMentions{},
 		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
 	})
+}
 
-	return err
+// compactSearchLine summarizes a course on a single line for the compact /search layout, without links or ratings
+func compactSearchLine(course Course) string {
+	when := "No Time"
+	where := "Online"
+	if len(course.MeetingsFaculty) > 0 {
+		meeting := course.MeetingsFaculty[0]
+		if _, _, ok := meetingClock(meeting); ok {
+			when = meeting.TimeString()
+		}
+		if mt := meeting.MeetingTime; mt.Room != "" {
+			where = fmt.Sprintf("%s %s", mt.Building, mt.Room)
+		}
+	}
+
+	instructor := "TBA"
+	if len(course.Faculty) > 0 {
+		instructor = course.Faculty[0].DisplayName
+	}
+
+	return fmt.Sprintf("%s `%s` **%s %s-%s** %s (%s cr) • %s • %s • %s", course.StatusEmoji(), course.CourseReferenceNumber, course.Subject, course.CourseNumber, course.SequenceNumber,
+		EscapeMarkdown(lo.Substring(course.CourseTitle, 0, 40)), course.CreditString(), when, where, EscapeMarkdown(instructor))
 }
 
 var TermCommandDefinition = &discordgo.ApplicationCommand{
@@ -258,7 +694,7 @@ var TermCommandDefinition = &discordgo.ApplicationCommand{
 	},
 }
 
-func TermCommandHandler(session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
+func TermCommandHandler(ctx context.Context, session *discordgo.Session, interaction *discordgo.InteractionCreate) error {
 	data := interaction.ApplicationCommandData()
 
 	searchTerm := ""
@@ -271,23 +707,27 @@ func TermCommandHandler(session *discordgo.Session, interaction *discordgo.Inter
 		case "page":
 			pageNumber = int(option.IntValue())
 		default:
-			log.Warn().Str("option", option.Name).Msg("Unexpected option in term command")
+			log.Ctx(ctx).Warn().Str("option", option.Name).Msg("Unexpected option in term command")
 		}
 	}
 
-	termResult, err := GetTerms(searchTerm, pageNumber, 25)
+	termResult, err := bannerClient.GetTerms(ctx, searchTerm, pageNumber, 25)
 
 	if err != nil {
-		RespondError(session, interaction.Interaction, "Error while fetching terms", err)
-		return err
+		return RespondErrorWithLevel(ctx, session, interaction.Interaction, ErrorLevelUpstream, "Error while fetching terms", err)
 	}
 
 	fields := []*discordgo.MessageEmbedField{}
 
-	for _, t := range termResult {
+	for _, t := range termResult.Terms {
+		value := t.Code
+		if t.Archived() {
+			value += " (View Only)"
+		}
+
 		fields = append(fields, &discordgo.MessageEmbedField{
-			Name:   t.Description,
-			Value:  t.Code,
+			Name:   t.Name(),
+			Value:  value,
 			Inline: true,
 		})
 	}
@@ -295,7 +735,14 @@ func TermCommandHandler(session *discordgo.Session, interaction *discordgo.Inter
 	fetch_time := time.Now()
 
 	if len(fields) > 25 {
-		log.Warn().Int("count", len(fields)).Msg("Too many fields in term command (trimmed)")
+		log.Ctx(ctx).Warn().Int("count", len(fields)).Msg("Too many fields in term command (trimmed)")
+	}
+
+	// The total is only known once the last page has been reached
+	count := len(termResult.Terms)
+	description := p.Sprintf(msgTermCount, count, pageNumber)
+	if total, ok := termResult.Total(); ok {
+		description = p.Sprintf(msgTermCountOfTotal, count, total, pageNumber, pageNumber)
 	}
 
 	err = session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
@@ -304,7 +751,7 @@ func TermCommandHandler(session *discordgo.Session, interaction *discordgo.Inter
 			Embeds: []*discordgo.MessageEmbed{
 				{
 					Footer:      GetFetchedFooter(fetch_time),
-					Description: p.Sprintf("%d of %d term%s (page %d)", len(termResult), len(terms), Plural(len(terms)), pageNumber),
+					Description: description,
 					Fields:      fields[:min(25, len(fields))],
 				},
 			},
@@ -328,56 +775,85 @@ var TimeCommandDefinition = &discordgo.ApplicationCommand{
 	},
 }
 
-func TimeCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+func TimeCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	fetch_time := time.Now()
 	crn := i.ApplicationCommandData().Options[0].IntValue()
+	term := ResolveTerm(ctx, i)
 
-	// Fix static term
-	meetingTimes, err := GetCourseMeetingTime(202510, int(crn))
+	// Looking up the meetings may take several requests (e.g. retrying in the CRN's own term), so defer the response
+	err := DeferResponse(ctx, s, i.Interaction)
 	if err != nil {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "Error getting meeting time",
-			},
-		})
 		return err
 	}
 
-	meetingTime := meetingTimes[0]
-	duration := meetingTime.EndTime().Sub(meetingTime.StartTime())
+	result, err := LookupMeetingTimes(ctx, term, int(crn))
+	var notFound *CourseNotFoundError
+	if errors.As(err, &notFound) {
+		return NewUserError("No course found with CRN %d in %s", crn, term.HumanName())
+	} else if err != nil {
+		return RespondErrorWithLevel(ctx, s, i.Interaction, ErrorLevelUpstream, "Error getting meeting time", err)
+	}
+
+	meetingTimes := result.Meetings
+	if len(meetingTimes) == 0 {
+		return NewUserError("CRN %d has no scheduled meetings", crn)
+	}
 
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{
-				{
-					Footer:      GetFetchedFooter(fetch_time),
-					Description: "",
-					Fields: []*discordgo.MessageEmbedField{
-						{
-							Name:  "Start Date",
-							Value: meetingTime.StartDay().Format("Monday, January 2, 2006"),
-						},
-						{
-							Name:  "End Date",
-							Value: meetingTime.EndDay().Format("Monday, January 2, 2006"),
-						},
-						{
-							Name:  "Start/End Time",
-							Value: fmt.Sprintf("%s - %s (%d min)", meetingTime.StartTime().String(), meetingTime.EndTime().String(), int64(duration.Minutes())),
-						},
-						{
-							Name:  "Days of Week",
-							Value: WeekdaysToString(meetingTime.Days()),
-						},
-					},
-				},
+	// Each meeting (e.g. lecture, lab) is shown as a group of fields
+	fields := []*discordgo.MessageEmbedField{}
+	shown := min(len(meetingTimes), maxEmbedFields/FieldsPerMeeting)
+	for _, meetingTime := range meetingTimes[:shown] {
+		timeText := "No scheduled time"
+		if start, end, ok := meetingClock(meetingTime); ok {
+			timeText = fmt.Sprintf("%s\n%s - %s", WeekdaysToString(meetingTime.Days()), start.String(), end.String())
+
+			// Meetings ending before they start are malformed, so no duration is shown
+			if duration := end.Sub(&start); duration > 0 {
+				timeText += fmt.Sprintf(" (%s)", FormatDuration(duration))
+			}
+		}
+
+		meetingType := meetingTime.MeetingTime.MeetingTypeDescription
+		if meetingType == "" {
+			meetingType = "Meeting"
+		}
+
+		dateText := "Dates TBD"
+		if meetingTime.HasDateRange() {
+			dateText = fmt.Sprintf("%s -\n%s", meetingTime.StartDay().Format("Mon, Jan 2, 2006"), meetingTime.EndDay().Format("Mon, Jan 2, 2006"))
+		}
+
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   meetingType,
+			Value:  dateText,
+			Inline: true,
+		}, &discordgo.MessageEmbedField{
+			Name:   "Days & Time",
+			Value:  timeText,
+			Inline: true,
+		}, &discordgo.MessageEmbedField{
+			Name:   "Location",
+			Value:  meetingTime.PlaceLink(),
+			Inline: true,
+		})
+	}
+
+	footer := GetFetchedFooter(fetch_time)
+	if shown < len(meetingTimes) {
+		footer.Text = fmt.Sprintf("Showing %d of %d meetings • %s", shown, len(meetingTimes), footer.Text)
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       fmt.Sprintf("CRN %d (%s)", crn, result.Term.HumanName()),
+				Footer:      footer,
+				Description: p.Sprintf(msgMeetingCount, len(meetingTimes)),
+				Fields:      fields,
 			},
-			AllowedMentions: &discordgo.MessageAllowedMentions{},
 		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
 	})
-	return nil
 }
 
 var IcsCommandDefinition = &discordgo.ApplicationCommand{
@@ -393,48 +869,62 @@ var IcsCommandDefinition = &discordgo.ApplicationCommand{
 	},
 }
 
-func IcsCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+func IcsCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	crn := i.ApplicationCommandData().Options[0].IntValue()
 
-	course, err := GetCourse(strconv.Itoa(int(crn)))
+	// Building the calendar requires multiple requests, so defer the response
+	err := DeferResponse(ctx, s, i.Interaction)
 	if err != nil {
-		return fmt.Errorf("Error retrieving course data: %w", err)
+		return err
 	}
 
-	// Fix static term
-	meetingTimes, err := GetCourseMeetingTime(202510, int(crn))
-	if err != nil {
-		return fmt.Errorf("Error requesting meeting time: %w", err)
+	// The meetings are looked up first, as they may be found in the CRN's actual term rather than the one requested
+	term := ResolveTerm(ctx, i)
+	result, err := LookupMeetingTimes(ctx, term, int(crn))
+	var notFound *CourseNotFoundError
+	if errors.As(err, &notFound) {
+		return NewUserError("No course found with CRN %d in %s", crn, term.HumanName())
+	} else if err != nil {
+		return fmt.Errorf("failed to request meeting time: %w", err)
 	}
 
-	if len(meetingTimes) == 0 {
-		return fmt.Errorf("unexpected - no meeting time data found for course")
+	course, err := bannerClient.FindCourseByCRN(ctx, result.Term.ToString(), strconv.Itoa(int(crn)))
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Int64("crn", crn).Msg("Course not found")
+		return NewUserError("No course found with CRN %d in %s", crn, result.Term.HumanName())
 	}
 
-	// Check if the course has any meeting times
-	_, exists := lo.Find(meetingTimes, func(mt MeetingTimeResponse) bool {
-		switch mt.MeetingTime.MeetingType {
-		case "ID", "OA":
-			return false
-		default:
-			return true
-		}
-	})
+	// Online asynchronous & arranged sections have no meeting times to put on a calendar
+	meetingTimes := result.Meetings
+	if !result.Scheduled() {
+		log.Ctx(ctx).Warn().Str("crn", course.CourseReferenceNumber).Msg("Non-meeting course requested for ICS file")
+		return RespondErrorWithLevel(ctx, s, i.Interaction, ErrorLevelUser, "The course requested does not meet at a defined moment in time.", nil)
+	}
 
-	if !exists {
-		log.Warn().Str("crn", course.CourseReferenceNumber).Msg("Non-meeting course requested for ICS file")
-		RespondError(s, i.Interaction, "The course requested does not meet at a defined moment in time.", nil)
-		return nil
+	// Sections may not have an instructor assigned yet
+	instructor := "TBA"
+	if len(course.Faculty) > 0 {
+		instructor = course.Faculty[0].DisplayName
 	}
 
 	events := []string{}
 	for _, meeting := range meetingTimes {
+		// Arranged or TBD meetings have no dates or times to recur over
+		if !meeting.HasDateRange() {
+			log.Ctx(ctx).Debug().Str("crn", meeting.CourseReferenceNumber).Msg("Skipping meeting without a date range")
+			continue
+		}
+
+		startTime, endTime, ok := meetingClock(meeting)
+		if !ok {
+			log.Ctx(ctx).Debug().Str("crn", meeting.CourseReferenceNumber).Msg("Skipping meeting without a start or end time")
+			continue
+		}
+
 		now := time.Now().In(CentralTimeLocation)
 		uid := fmt.Sprintf("%d-%s@ical.banner.xevion.dev", now.Unix(), meeting.CourseReferenceNumber)
 
 		startDay := meeting.StartDay()
-		startTime := meeting.StartTime()
-		endTime := meeting.EndTime()
 		dtStart := time.Date(startDay.Year(), startDay.Month(), startDay.Day(), int(startTime.Hours), int(startTime.Minutes), 0, 0, CentralTimeLocation)
 		dtEnd := time.Date(startDay.Year(), startDay.Month(), startDay.Day(), int(endTime.Hours), int(endTime.Minutes), 0, 0, CentralTimeLocation)
 
@@ -442,7 +932,7 @@ func IcsCommandHandler(s *discordgo.Session, i *discordgo.InteractionCreate) err
 		until := time.Date(endDay.Year(), endDay.Month(), endDay.Day(), 23, 59, 59, 0, CentralTimeLocation)
 
 		summary := fmt.Sprintf("%s %s %s", course.Subject, course.CourseNumber, course.CourseTitle)
-		description := fmt.Sprintf("Instructor: %s\nSection: %s\nCRN: %s", course.Faculty[0].DisplayName, course.SequenceNumber, meeting.CourseReferenceNumber)
+		description := fmt.Sprintf("Instructor: %s\nSection: %s\nCRN: %s", instructor, course.SequenceNumber, meeting.CourseReferenceNumber)
 		location := meeting.PlaceString()
 
 		event := fmt.Sprintf(`BEGIN:VEVENT
@@ -459,6 +949,10 @@ END:VEVENT`, now.Format(ICalTimestampFormatLocal), uid, dtStart.Format(ICalTimes
 		events = append(events, event)
 	}
 
+	if len(events) == 0 {
+		return RespondErrorWithLevel(ctx, s, i.Interaction, ErrorLevelUser, "The course requested does not have any meetings with defined dates and times yet.", nil)
+	}
+
 	// TODO: Make this dynamically requested, parsed & cached from tzurl.org
 	vTimezone := `BEGIN:VTIMEZONE
 TZID:America/Chicago
@@ -489,18 +983,1134 @@ CALSCALE:GREGORIAN
 %s
 END:VCALENDAR`, vTimezone, strings.Join(events, "\n"))
 
-	session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Files: []*discordgo.File{
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Files: []*discordgo.File{
+			{
+				Name:        fmt.Sprintf("%s-%s-%s_%s.ics", course.Subject, course.CourseNumber, course.SequenceNumber, course.CourseReferenceNumber),
+				ContentType: "text/calendar",
+				Reader:      strings.NewReader(ics),
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+var BuildingsCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "buildings",
+	Description: "List building codes and their names",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "search",
+			Description: "Building code or name to search for (e.g. SP1, Data Science)",
+			Required:    false,
+		},
+	},
+}
+
+func BuildingsCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	fetch_time := time.Now()
+	search := ""
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "search":
+			search = strings.ToLower(strings.TrimSpace(option.StringValue()))
+		}
+	}
+
+	buildings, err := GetBuildings(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Filter & sort by building code
+	codes := lo.Filter(lo.Keys(buildings), func(code string, _ int) bool {
+		return search == "" || strings.Contains(strings.ToLower(code), search) || strings.Contains(strings.ToLower(buildings[code]), search)
+	})
+	sort.Strings(codes)
+
+	// Build the list, stopping before the embed description limit
+	var sb strings.Builder
+	shown := 0
+	for _, code := range codes {
+		line := fmt.Sprintf("`%s` %s\n", code, buildings[code])
+		if sb.Len()+len(line) > 4000 {
+			break
+		}
+		sb.WriteString(line)
+		shown++
+	}
+
+	if len(codes) == 0 {
+		sb.WriteString("No buildings found.")
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       p.Sprintf(msgBuildingCountOf, shown, len(codes)),
+				Footer:      GetFetchedFooter(fetch_time),
+				Description: sb.String(),
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+var RescrapeCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "rescrape",
+	Description: "Immediately re-scrape a subject (admin only)",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "subject",
+			Description: "Subject code (e.g. CS, MAT)",
+			Required:    true,
+		},
+	},
+}
+
+func RescrapeCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	subject := strings.ToUpper(strings.TrimSpace(i.ApplicationCommandData().Options[0].StringValue()))
+	if len(AllMajors) > 0 && !lo.Contains(AllMajors, subject) {
+		return NewUserError("Unknown subject code: %s", subject)
+	}
+
+	// Scraping a subject can take a while, so defer the response
+	err := DeferResponse(ctx, s, i.Interaction)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = RescrapeMajor(ctx, subject)
+	if err != nil {
+		return fmt.Errorf("failed to rescrape %s: %w", subject, err)
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Footer:      GetFetchedFooter(time.Now()),
+				Description: fmt.Sprintf("Re-scraped %s in %s", subject, time.Since(start).Round(time.Millisecond)),
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+var ConfigCommandDefinition = &discordgo.ApplicationCommand{
+	Name:                     "config",
+	Description:              "View or change this server's configuration",
+	DefaultMemberPermissions: GetInt64Pointer(discordgo.PermissionManageServer),
+	DMPermission:             GetBoolPointer(false),
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "subject",
+			Description: "Default subject for /search (e.g. CS), or 'none' to clear",
+			Required:    false,
+		},
+		{
+			Type:         discordgo.ApplicationCommandOptionChannel,
+			Name:         "notifications",
+			Description:  "Channel to send notifications to",
+			Required:     false,
+			ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "digest",
+			Description: "Subjects to post newly opened sections of to the notification channel (e.g. CS, MAT), or 'none'",
+			Required:    false,
+		},
+	},
+}
+
+func ConfigCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.GuildID == "" {
+		return NewUserError("This command can only be used within a server.")
+	}
+
+	config, err := GetGuildConfig(ctx, i.GuildID)
+	if err != nil {
+		return err
+	}
+
+	// Apply any changes
+	options := i.ApplicationCommandData().Options
+	for _, option := range options {
+		switch option.Name {
+		case "subject":
+			subject := strings.ToUpper(strings.TrimSpace(option.StringValue()))
+			if subject == "NONE" {
+				subject = ""
+			} else if len(AllMajors) > 0 && !lo.Contains(AllMajors, subject) {
+				return NewUserError("Unknown subject code: %s", subject)
+			}
+			config.DefaultSubject = subject
+		case "notifications":
+			config.NotificationChannel = option.ChannelValue(nil).ID
+		case "digest":
+			subjects := ParseSubjects(option.StringValue())
+			if len(subjects) == 1 && subjects[0] == "NONE" {
+				subjects = nil
+			} else if unknown := lo.Filter(subjects, func(subject string, _ int) bool {
+				return len(AllMajors) > 0 && !lo.Contains(AllMajors, subject)
+			}); len(unknown) > 0 {
+				return NewUserError("Unknown subject code: %s", strings.Join(unknown, ", "))
+			}
+
+			err = SetDigestSubjects(ctx, i.GuildID, config, subjects)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(options) > 0 {
+		err = SetGuildConfig(ctx, i.GuildID, config)
+		if err != nil {
+			return err
+		}
+	}
+
+	valueOrNone := func(value string) string {
+		if value == "" {
+			return "None"
+		}
+		return value
+	}
+
+	notificationChannel := "None"
+	if config.NotificationChannel != "" {
+		notificationChannel = fmt.Sprintf("<#%s>", config.NotificationChannel)
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title: "Server Configuration",
+				Fields: []*discordgo.MessageEmbedField{
+					{
+						Name:   "Default Subject",
+						Value:  valueOrNone(config.DefaultSubject),
+						Inline: true,
+					},
+					{
+						Name:   "Notification Channel",
+						Value:  notificationChannel,
+						Inline: true,
+					},
+					{
+						Name:   "Digest Subjects",
+						Value:  valueOrNone(strings.Join(config.DigestSubjects, ", ")),
+						Inline: true,
+					},
+				},
+			},
+		},
+		Flags:           discordgo.MessageFlagsEphemeral,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+var HistoryCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "history",
+	Description: "Show how a course has changed over the term",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "crn",
+			Description: "Course Reference Number",
+			Required:    true,
+		},
+	},
+}
+
+func HistoryCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	fetch_time := time.Now()
+	crn := int(i.ApplicationCommandData().Options[0].IntValue())
+
+	course, err := bannerClient.GetCourse(ctx, strconv.Itoa(crn))
+	if err != nil {
+		return NewUserError("No course found with CRN %d", crn)
+	}
+
+	history, err := GetCourseHistory(ctx, course.Term, crn)
+	if err != nil {
+		return err
+	}
+
+	// Show the most recent changes that fit within the embed, in chronological order
+	lines := []string{}
+	length := 0
+	for index := len(history) - 1; index >= 0; index-- {
+		change := history[index]
+		line := fmt.Sprintf("<t:%d:f> **%s** %s → %s", change.Time.Unix(), change.Field, EscapeMarkdown(change.Old), EscapeMarkdown(change.New))
+		if length+len(line)+1 > 4000 {
+			break
+		}
+		lines = append(lines, line)
+		length += len(line) + 1
+	}
+	lines = lo.Reverse(lines)
+
+	if len(lines) == 0 {
+		lines = append(lines, "No changes have been recorded for this course.")
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       fmt.Sprintf("%s %s-%s (CRN %d)", course.Subject, course.CourseNumber, course.SequenceNumber, crn),
+				Footer:      GetFetchedFooter(fetch_time),
+				Description: strings.Join(lines, "\n"),
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+var SelfTestCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "selftest",
+	Description: "Check that each Banner endpoint is working (admin only)",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "crn",
+			Description: "Course Reference Number to fetch meeting times for (defaults to a search result)",
+			Required:    false,
+		},
+	},
+}
+
+func SelfTestCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Each endpoint may take up to the request timeout, so defer the response
+	err := DeferResponse(ctx, s, i.Interaction)
+	if err != nil {
+		return err
+	}
+
+	crn := 0
+	if len(i.ApplicationCommandData().Options) > 0 {
+		crn = int(i.ApplicationCommandData().Options[0].IntValue())
+	}
+	term := Default(time.Now()).ToString()
+
+	lines := []string{}
+	failures := 0
+	check := func(name string, call func() error) {
+		start := time.Now()
+		err := call()
+		elapsed := time.Since(start).Round(time.Millisecond)
+
+		if err != nil {
+			failures++
+			log.Ctx(ctx).Warn().Err(err).Str("endpoint", name).Dur("elapsed", elapsed).Msg("Self-test failed")
+			lines = append(lines, fmt.Sprintf("❌ `%s` %s: %s", name, elapsed, err))
+		} else {
+			lines = append(lines, fmt.Sprintf("✅ `%s` %s", name, elapsed))
+		}
+	}
+
+	check("GetTerms", func() error {
+		_, err := bannerClient.GetTerms(ctx, "", 1, 10)
+		return err
+	})
+
+	check("GetSubjects", func() error {
+		_, err := bannerClient.GetSubjects(ctx, "", term, 1, 10)
+		return err
+	})
+
+	check("Search", func() error {
+		result, err := bannerClient.Search(ctx, NewQuery().Subject("CS").MaxResults(1), "subjectDescription", false)
+		if err != nil {
+			return err
+		}
+		if !result.Success {
+			return fmt.Errorf("result marked unsuccessful")
+		}
+
+		// Use the first result for the meeting time check, if no CRN was given
+		if crn == 0 && len(result.Data) > 0 {
+			crn, _ = strconv.Atoi(result.Data[0].CourseReferenceNumber)
+			term = result.Data[0].Term
+		}
+		return nil
+	})
+
+	check("GetCourseMeetingTime", func() error {
+		if crn == 0 {
+			return fmt.Errorf("no CRN available to check")
+		}
+
+		termValue, err := strconv.Atoi(term)
+		if err != nil {
+			return fmt.Errorf("invalid term %s: %w", term, err)
+		}
+
+		_, err = bannerClient.GetCourseMeetingTime(ctx, termValue, crn)
+		return err
+	})
+
+	color := 0x2ECC71
+	if failures > 0 {
+		color = ErrorLevelUpstream.Color()
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       fmt.Sprintf("Self-test: %d/%d endpoints healthy", len(lines)-failures, len(lines)),
+				Color:       color,
+				Footer:      GetFetchedFooter(time.Now()),
+				Description: strings.Join(lines, "\n"),
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+// FeedbackCooldown is how long a user must wait between feedback reports
+const FeedbackCooldown = 5 * time.Minute
+
+var FeedbackCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "feedback",
+	Description: "Report a bug or send feedback to the developers",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "message",
+			Description: "What happened, or what could be better",
+			Required:    true,
+			MinLength:   GetIntPointer(10),
+			MaxLength:   1000,
+		},
+	},
+}
+
+func FeedbackCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if feedbackChannelID == "" {
+		return NewUserError("Feedback is not enabled for this bot.")
+	}
+
+	user := GetUser(i)
+	message := strings.TrimSpace(i.ApplicationCommandData().Options[0].StringValue())
+
+	// Only allow one report per user within the cooldown
+	allowed, err := kv.SetNX(ctx, FeedbackCooldownKey(user.ID), 1, FeedbackCooldown).Result()
+	if err != nil {
+		return fmt.Errorf("failed to check feedback cooldown: %w", err)
+	}
+	if !allowed {
+		return NewUserError("You've recently sent feedback, please wait a few minutes before sending more.")
+	}
+
+	source := "Direct Message"
+	if i.GuildID != "" {
+		source = fmt.Sprintf("%s (%s)", GetGuildName(i.GuildID), i.GuildID)
+	}
+
+	_, err = s.ChannelMessageSendEmbed(feedbackChannelID, &discordgo.MessageEmbed{
+		Title:       "Feedback",
+		Description: EscapeMarkdown(message),
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "User",
+				Value:  fmt.Sprintf("%s (%s)", EscapeMarkdown(user.Username), user.ID),
+				Inline: true,
+			},
+			{
+				Name:   "Source",
+				Value:  EscapeMarkdown(source),
+				Inline: true,
+			},
+		},
+	})
+	if err != nil {
+		// The feedback never arrived, so the user may try again right away
+		if delErr := kv.Del(ctx, FeedbackCooldownKey(user.ID)).Err(); delErr != nil {
+			log.Ctx(ctx).Warn().Err(delErr).Str("user", user.ID).Msg("Failed to clear feedback cooldown")
+		}
+		return fmt.Errorf("failed to send feedback: %w", err)
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Content:         "Thanks, your feedback has been sent!",
+		Flags:           discordgo.MessageFlagsEphemeral,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+var SeatsCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "seats",
+	Description: "Check the enrollment and waitlist of a course",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "crn",
+			Description: "Course Reference Number",
+			Required:    true,
+		},
+	},
+}
+
+func SeatsCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	crn := strconv.FormatInt(i.ApplicationCommandData().Options[0].IntValue(), 10)
+
+	// Seat counts change quickly, so a live search is used
+	err := DeferResponse(ctx, s, i.Interaction)
+	if err != nil {
+		return err
+	}
+
+	course, err := bannerClient.FindCourseByCRN(ctx, ResolveTerm(ctx, i).ToString(), crn)
+	if err != nil {
+		return NewUserError("No course found with CRN %s", crn)
+	}
+	fetch_time := time.Now()
+
+	// Estimate where the user would land on the waitlist
+	var estimate string
+	switch {
+	case course.SeatsAvailable > 0:
+		estimate = "Seats are open, no waitlist needed."
+	case course.WaitCapacity == 0:
+		estimate = "This course has no waitlist."
+	case course.WaitCount >= course.WaitCapacity:
+		estimate = "The waitlist is full."
+	default:
+		estimate = fmt.Sprintf("You'd be approximately #%d on the waitlist.", course.WaitCount+1)
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       fmt.Sprintf("%s %s-%s (CRN %s)", course.Subject, course.CourseNumber, course.SequenceNumber, crn),
+				Footer:      GetFetchedFooter(fetch_time),
+				Description: estimate,
+				Fields: []*discordgo.MessageEmbedField{
+					{
+						Name:  "Enrollment",
+						Value: fmt.Sprintf("`%s` %d of %d seats taken", ProgressBar(course.Enrollment, course.MaximumEnrollment, 12), course.Enrollment, course.MaximumEnrollment),
+					},
+					{
+						Name:  "Waitlist",
+						Value: fmt.Sprintf("`%s` %d of %d spots taken", ProgressBar(course.WaitCount, course.WaitCapacity, 12), course.WaitCount, course.WaitCapacity),
+					},
+				},
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+var CoverageCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "coverage",
+	Description: "Show how many sections are cached for each subject this term",
+}
+
+func CoverageCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	term := Default(time.Now())
+	subjects := AllMajors
+	if len(subjects) == 0 {
+		subjects = PriorityMajors
+	}
+	subjects = ScrapableSubjects(subjects)
+
+	values, err := kv.MGet(ctx, lo.Map(subjects, func(subject string, _ int) string {
+		return ScrapedKey(subject, term.ToString())
+	})...).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get scraped subjects: %w", err)
+	}
+
+	// Subjects without a marker have expired or were never scraped; -1 marks a scrape that found nothing
+	counts := map[string]int{}
+	missing := []string{}
+	for index, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			missing = append(missing, subjects[index])
+			continue
+		}
+
+		count, err := strconv.Atoi(raw)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("subject", subjects[index]).Str("value", raw).Msg("Invalid scraped count")
+			missing = append(missing, subjects[index])
+			continue
+		}
+		counts[subjects[index]] = max(0, count)
+	}
+
+	covered := lo.Keys(counts)
+	sort.Slice(covered, func(a, b int) bool {
+		if counts[covered[a]] != counts[covered[b]] {
+			return counts[covered[a]] > counts[covered[b]]
+		}
+		return covered[a] < covered[b]
+	})
+
+	var description strings.Builder
+	for _, subject := range covered {
+		line := fmt.Sprintf("`%s` %s\n", subject, p.Sprintf(msgClassCount, counts[subject]))
+		if description.Len()+len(line) > 3500 {
+			description.WriteString("…\n")
+			break
+		}
+		description.WriteString(line)
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		fmt.Fprintf(&description, "\n**Not cached:** %s", lo.Substring(strings.Join(missing, ", "), 0, 500))
+	}
+
+	total := lo.Sum(lo.Values(counts))
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       fmt.Sprintf("%s Coverage: %d/%d subjects, %s", term.HumanName(), len(covered), len(subjects), p.Sprintf(msgClassCount, total)),
+				Footer:      GetFetchedFooter(time.Now()),
+				Description: description.String(),
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+var ResearchCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "research",
+	Description: "Re-run one of your recent searches",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "index",
+			Description: "Which recent search to run (1 is the most recent), omit to list them",
+			Required:    false,
+			MinValue:    GetFloatPointer(1),
+			MaxValue:    MaxRecentSearches,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "remember",
+			Description: "Remember your searches (true), or forget & stop remembering them (false)",
+			Required:    false,
+		},
+	},
+}
+
+func ResearchCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	user := GetUser(i)
+	index := 0
+
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "index":
+			index = int(option.IntValue())
+		case "remember":
+			remember := option.BoolValue()
+			err := SetRecordingSearches(ctx, user.ID, remember)
+			if err != nil {
+				return err
+			}
+
+			content := "Your searches will be remembered, use `/research` to run them again."
+			if !remember {
+				content = "Your recent searches have been forgotten, and will no longer be remembered."
+			}
+			return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			})
+		}
+	}
+
+	searches, err := GetRecentSearches(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(searches) == 0 {
+		enabled, err := IsRecordingSearches(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return NewUserError("Your searches aren't being remembered, use `/research remember:True` to opt in.")
+		}
+		return NewUserError("You have no recent searches.")
+	}
+
+	// List the recent searches
+	if index == 0 {
+		lines := lo.Map(searches, func(search RecentSearch, index int) string {
+			return fmt.Sprintf("`%d` %s", index+1, EscapeMarkdown(search.Description))
+		})
+		return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
 				{
-					Name:        fmt.Sprintf("%s-%s-%s_%s.ics", course.Subject, course.CourseNumber, course.SequenceNumber, course.CourseReferenceNumber),
-					ContentType: "text/calendar",
-					Reader:      strings.NewReader(ics),
+					Title:       "Recent Searches",
+					Description: strings.Join(lines, "\n"),
 				},
 			},
+			Flags:           discordgo.MessageFlagsEphemeral,
 			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		})
+	}
+
+	if index > len(searches) {
+		return NewUserError("You only have %d recent searches.", len(searches))
+	}
+
+	return RunSearch(ctx, s, i, searches[index-1].Options, false)
+}
+
+var ConflictsCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "conflicts",
+	Description: "Check a set of courses for overlapping meeting times",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "crns",
+			Description: "Course Reference Numbers, comma separated (e.g. 12345, 12346)",
+			Required:    true,
+		},
+	},
+}
+
+func ConflictsCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	crns, err := ParseCRNs(i.ApplicationCommandData().Options[0].StringValue())
+	if err != nil {
+		return err
+	}
+
+	// Each course may require a search, so defer the response
+	err = DeferResponse(ctx, s, i.Interaction)
+	if err != nil {
+		return err
+	}
+
+	courses, err := FetchCourses(ctx, ResolveTerm(ctx, i).ToString(), crns)
+	if err != nil {
+		return err
+	}
+	fetch_time := time.Now()
+
+	name := func(course Course) string {
+		return fmt.Sprintf("%s %s-%s (CRN %s)", course.Subject, course.CourseNumber, course.SequenceNumber, course.CourseReferenceNumber)
+	}
+
+	lines := []string{}
+	for index, a := range courses {
+		for _, b := range courses[index+1:] {
+			conflicting, conflicts := a.ConflictsWith(&b)
+			if !conflicting {
+				continue
+			}
+
+			overlaps := lo.Map(conflicts, func(conflict Conflict, _ int) string {
+				return fmt.Sprintf("%s %s-%s", conflict.Day.String()[:3], conflict.Start.String(), conflict.End.String())
+			})
+			lines = append(lines, fmt.Sprintf("**%s** and **%s**: %s", name(a), name(b), strings.Join(overlaps, ", ")))
+		}
+	}
+
+	color := 0x2ECC71
+	description := fmt.Sprintf("No conflicts between %d courses.", len(courses))
+	if len(lines) > 0 {
+		color = 0xFF6500
+		description = lo.Substring(strings.Join(lines, "\n"), 0, 4000)
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       "Schedule Conflicts",
+				Footer:      GetFetchedFooter(fetch_time),
+				Description: description,
+				Color:       color,
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+var ScheduleCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "schedule",
+	Description: "Summarize the credit load and weekly meetings of a set of courses",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "crns",
+			Description: "Course Reference Numbers, comma separated (e.g. 12345, 12346)",
+			Required:    true,
+		},
+	},
+}
+
+func ScheduleCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	crns, err := ParseCRNs(i.ApplicationCommandData().Options[0].StringValue())
+	if err != nil {
+		return err
+	}
+
+	// Each course may require a search, so defer the response
+	err = DeferResponse(ctx, s, i.Interaction)
+	if err != nil {
+		return err
+	}
+
+	courses, err := FetchCourses(ctx, ResolveTerm(ctx, i).ToString(), crns)
+	if err != nil {
+		return err
+	}
+	fetch_time := time.Now()
+
+	// Total the credits (variable-credit courses widen the range) and weekly contact hours
+	creditLow, creditHigh := 0, 0
+	contactHours := 0.0
+	unscheduled := []string{}
+	type slot struct {
+		start NaiveTime
+		text  string
+	}
+	week := map[time.Weekday][]slot{}
+
+	for _, course := range courses {
+		low, high := course.CreditRange()
+		creditLow += low
+		creditHigh += high
+
+		label := course.Subject + course.CourseNumber
+		scheduled := false
+		for _, meeting := range course.MeetingsFaculty {
+			contactHours += meeting.MeetingTime.HoursWeek
+
+			start, end, ok := meetingClock(meeting)
+			if !ok {
+				continue
+			}
+			scheduled = true
+
+			for _, day := range meetingWeekdays(meeting) {
+				week[day] = append(week[day], slot{start: start, text: fmt.Sprintf("%s %s-%s", label, start.String(), end.String())})
+			}
+		}
+
+		// Online asynchronous & arranged sections have no place on the grid
+		if !scheduled {
+			unscheduled = append(unscheduled, label)
+		}
+	}
+
+	// Render a compact weekly grid, skipping weekends without classes
+	var grid strings.Builder
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		slots := week[day]
+		if len(slots) == 0 && (day == time.Sunday || day == time.Saturday) {
+			continue
+		}
+
+		sort.Slice(slots, func(a, b int) bool {
+			return slots[a].start.Before(slots[b].start)
+		})
+		texts := lo.Map(slots, func(slot slot, _ int) string { return slot.text })
+		if len(texts) == 0 {
+			texts = []string{"-"}
+		}
+		fmt.Fprintf(&grid, "%s  %s\n", day.String()[:3], strings.Join(texts, ", "))
+	}
+
+	credits := strconv.Itoa(creditLow)
+	if creditHigh != creditLow {
+		credits = fmt.Sprintf("%d-%d", creditLow, creditHigh)
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "Credit Hours",
+			Value:  credits,
+			Inline: true,
+		},
+		{
+			Name:   "Weekly Contact Hours",
+			Value:  strconv.FormatFloat(contactHours, 'f', -1, 64),
+			Inline: true,
+		},
+	}
+	if len(unscheduled) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Unscheduled",
+			Value:  strings.Join(unscheduled, ", "),
+			Inline: true,
+		})
+	}
+
+	// Only the conflicting pairs are listed, /conflicts shows exactly when they overlap
+	conflicting := []string{}
+	for index := range courses {
+		for other := index + 1; other < len(courses); other++ {
+			if ok, _ := courses[index].ConflictsWith(&courses[other]); ok {
+				conflicting = append(conflicting, fmt.Sprintf("%s%s & %s%s", courses[index].Subject, courses[index].CourseNumber, courses[other].Subject, courses[other].CourseNumber))
+			}
+		}
+	}
+	if len(conflicting) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "⚠️ Conflicts",
+			Value:  lo.Substring(strings.Join(conflicting, "\n"), 0, 1024),
+			Inline: false,
+		})
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       p.Sprintf("Schedule: %s", p.Sprintf(msgClassCount, len(courses))),
+				Footer:      GetFetchedFooter(fetch_time),
+				Description: lo.Substring(fmt.Sprintf("```\n%s```", grid.String()), 0, 4000),
+				Fields:      fields,
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+var SetTermCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "setterm",
+	Description: "Use a specific term for your commands in this channel",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "code",
+			Description: "Term code (e.g. 202520), leave empty to return to the default term",
+			Required:    false,
+			MinLength:   GetIntPointer(6),
+			MaxLength:   6,
+		},
+	},
+}
+
+func SetTermCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	user := GetUser(i)
+	options := i.ApplicationCommandData().Options
+
+	var content string
+	if len(options) == 0 {
+		err := ClearActiveTerm(ctx, i.ChannelID, user.ID)
+		if err != nil {
+			return err
+		}
+
+		content = fmt.Sprintf("Your commands in this channel now use the default term, %s.", Default(time.Now()).HumanName())
+	} else {
+		code := strings.TrimSpace(options[0].StringValue())
+		if !IsValidTermCode(code) {
+			return NewUserError("Invalid term code: %s (e.g. 202520)", code)
+		}
+
+		bannerTerm, err := FindBannerTerm(ctx, code)
+		if err != nil {
+			return RespondErrorWithLevel(ctx, s, i.Interaction, ErrorLevelUpstream, "Error while fetching terms", err)
+		}
+		if bannerTerm == nil {
+			return NewUserError("Term %s does not exist, see `/terms` for available terms", code)
+		}
+
+		term := ParseTerm(code)
+		err = SetActiveTerm(ctx, i.ChannelID, user.ID, term)
+		if err != nil {
+			return err
+		}
+
+		content = fmt.Sprintf("Your commands in this channel now use %s (%s) for the next %d hours.", term.HumanName(), code, int(ActiveTermTTL.Hours()))
+		if bannerTerm.Archived() {
+			content += "\n⚠️ This term is archived (view only), so results may be incomplete."
+		}
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Content:         content,
+		Flags:           discordgo.MessageFlagsEphemeral,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+var WhichTermCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "whichterm",
+	Description: "Find the term a date falls in",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "date",
+			Description: "Date in MM/DD/YYYY format (e.g. 09/15/2024)",
+			Required:    true,
+			MaxLength:   10,
+		},
+	},
+}
+
+func WhichTermCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	raw := strings.TrimSpace(i.ApplicationCommandData().Options[0].StringValue())
+	date, err := time.ParseInLocation(layout, raw, CentralTimeLocation)
+	if err != nil {
+		return NewUserError("Invalid date: %s (use MM/DD/YYYY, e.g. 09/15/2024)", raw)
+	}
+
+	currentTerm, nextTerm := GetCurrentTerm(date)
+
+	current := "Between terms"
+	if currentTerm != nil {
+		current = fmt.Sprintf("%s (%s)", currentTerm.HumanName(), currentTerm.ToString())
+	}
+	next := "None"
+	if nextTerm != nil {
+		next = fmt.Sprintf("%s (%s)", nextTerm.HumanName(), nextTerm.ToString())
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title: date.Format("Monday, January 2, 2006"),
+				Fields: []*discordgo.MessageEmbedField{
+					{
+						Name:   "Term",
+						Value:  current,
+						Inline: true,
+					},
+					{
+						Name:   "Next Term",
+						Value:  next,
+						Inline: true,
+					},
+				},
+				Color: 0x0073FF,
+			},
+		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+var RawCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "raw",
+	Description: "Dump the parsed course data for debugging (admin only)",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "crn",
+			Description: "Course Reference Number",
+			Required:    true,
+		},
+	},
+}
+
+// RawCommandHandler attaches the full parsed course as JSON, including Banner's internal fields (e.g. category, class) that are otherwise unused
+func RawCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	crn := strconv.FormatInt(i.ApplicationCommandData().Options[0].IntValue(), 10)
+
+	err := DeferResponse(ctx, s, i.Interaction)
+	if err != nil {
+		return err
+	}
+
+	course, err := bannerClient.FindCourseByCRN(ctx, ResolveTerm(ctx, i).ToString(), crn)
+	if err != nil {
+		return NewUserError("No course found with CRN %s", crn)
+	}
+
+	raw, err := json.MarshalIndent(course, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal course: %w", err)
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Content: fmt.Sprintf("Parsed data for CRN %s (%s)", crn, EscapeMarkdown(course.CourseTitle)),
+		Files: []*discordgo.File{
+			{
+				Name:        fmt.Sprintf("%s_%s.json", course.Term, course.CourseReferenceNumber),
+				ContentType: JsonContentType,
+				Reader:      bytes.NewReader(raw),
+			},
+		},
+		Flags:           discordgo.MessageFlagsEphemeral,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+}
+
+// EnrollStatsSections is the number of most & least full sections listed by /enrollstats
+const EnrollStatsSections = 3
+
+var EnrollStatsCommandDefinition = &discordgo.ApplicationCommand{
+	Name:        "enrollstats",
+	Description: "Show the overall fill rate of a subject's cached sections",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "subject",
+			Description: "Subject code (e.g. CS)",
+			Required:    true,
+		},
+	},
+}
+
+func EnrollStatsCommandHandler(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	subject := strings.ToUpper(strings.TrimSpace(i.ApplicationCommandData().Options[0].StringValue()))
+	term := ResolveTerm(ctx, i)
+
+	courses, err := GetSubjectCourses(ctx, term.ToString(), subject)
+	if err != nil {
+		return err
+	}
+
+	if len(courses) == 0 {
+		return NewUserError("No cached sections found for `%s` in %s. The subject may not exist, or hasn't been scraped yet.", EscapeMarkdown(subject), term.HumanName())
+	}
+
+	stats := ComputeEnrollmentStats(courses)
+	section := func(course Course) string {
+		return fmt.Sprintf("`%s` %s %s-%s: %d/%d (%.0f%%)", course.CourseReferenceNumber, course.Subject, course.CourseNumber, course.SequenceNumber, course.Enrollment, course.MaximumEnrollment, FillRate(course)*100)
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "Enrolled",
+			Value:  fmt.Sprintf("`%s` %d of %d seats (%.1f%%)", ProgressBar(stats.Enrolled, stats.Capacity, 12), stats.Enrolled, stats.Capacity, stats.FillPercent()),
+			Inline: false,
+		},
+	}
+
+	if len(stats.ByFill) > 0 {
+		shown := min(len(stats.ByFill), EnrollStatsSections)
+		least := make([]Course, 0, shown)
+		for index := len(stats.ByFill) - 1; index >= len(stats.ByFill)-shown; index-- {
+			least = append(least, stats.ByFill[index])
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Most Full",
+			Value:  strings.Join(lo.Map(stats.ByFill[:shown], func(course Course, _ int) string { return section(course) }), "\n"),
+			Inline: false,
+		}, &discordgo.MessageEmbedField{
+			Name:   "Least Full",
+			Value:  strings.Join(lo.Map(least, func(course Course, _ int) string { return section(course) }), "\n"),
+			Inline: false,
+		})
+	}
+
+	return Respond(ctx, s, i.Interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       fmt.Sprintf("%s Enrollment (%s)", EscapeMarkdown(subject), term.HumanName()),
+				Description: p.Sprintf(msgClassCount, stats.Sections),
+				Footer:      GetFetchedFooter(time.Now()),
+				Fields:      fields,
+				Color:       0x0073FF,
+			},
 		},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
 	})
-	return nil
 }
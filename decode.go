@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// lenientNumberFields are the numeric Course fields Banner has been seen sending with a different type (e.g. "3" or 3.0 instead of 3)
+var lenientNumberFields = []string{
+	"creditHours", "creditHourHigh", "creditHourLow",
+	"maximumEnrollment", "enrollment", "seatsAvailable", "waitCapacity", "waitCount",
+	"crossListCapacity", "crossListCount", "crossListAvailable",
+}
+
+// coerceInteger converts a JSON string or fractional number into a JSON integer, returning false if it is already an integer (or null).
+// Values that cannot be converted become null.
+func coerceInteger(raw json.RawMessage) (json.RawMessage, bool) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return raw, false
+	}
+
+	text := string(raw)
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return json.RawMessage("null"), true
+		}
+		text = strings.TrimSpace(text)
+	} else if !strings.ContainsAny(text, ".eE") {
+		return raw, false
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return json.RawMessage("null"), true
+	}
+	return json.RawMessage(strconv.Itoa(int(math.Round(value)))), true
+}
+
+// UnmarshalJSON decodes a course, coercing numeric fields that Banner sent with another type rather than failing entirely
+func (course *Course) UnmarshalJSON(data []byte) error {
+	// The alias has no methods, so decoding it doesn't recurse
+	type plainCourse Course
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	coerced := false
+	for _, name := range lenientNumberFields {
+		raw, exists := fields[name]
+		if !exists {
+			continue
+		}
+
+		if value, changed := coerceInteger(raw); changed {
+			log.Warn().Str("field", name).Str("raw", string(raw)).Str("value", string(value)).Msg("Coerced course field")
+			fields[name] = value
+			coerced = true
+		}
+	}
+
+	if coerced {
+		var err error
+		data, err = json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+	}
+
+	return json.Unmarshal(data, (*plainCourse)(course))
+}
+
+// UnmarshalJSON decodes a search result, skipping any course that cannot be decoded rather than dropping the whole page
+func (result *SearchResult) UnmarshalJSON(data []byte) error {
+	type plainSearchResult SearchResult
+	var raw struct {
+		plainSearchResult
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*result = SearchResult(raw.plainSearchResult)
+	result.Data = make([]Course, 0, len(raw.Data))
+	for i, rawCourse := range raw.Data {
+		var course Course
+		if err := json.Unmarshal(rawCourse, &course); err != nil {
+			log.Error().Err(err).Int("index", i).Str("raw", truncate(string(rawCourse), 500)).Msg("Skipping course that could not be decoded")
+			continue
+		}
+		result.Data = append(result.Data, course)
+	}
+
+	return nil
+}
+
+// truncate shortens a string for logging, noting how much was cut
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return fmt.Sprintf("%s... (%d more)", s[:length], len(s)-length)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+)
+
+// MaxDigestSize is the maximum number of opened sections buffered between digests; the oldest are discarded first
+const MaxDigestSize = 1000
+
+// OpenedSection is a section that transitioned from closed to open while scraping, buffered until the next digest
+type OpenedSection struct {
+	CourseReferenceNumber string    `json:"crn"`
+	Subject               string    `json:"subject"`
+	CourseNumber          string    `json:"courseNumber"`
+	SequenceNumber        string    `json:"sequenceNumber"`
+	Title                 string    `json:"title"`
+	SeatsAvailable        int       `json:"seatsAvailable"`
+	Time                  time.Time `json:"time"`
+}
+
+func (section OpenedSection) MarshalBinary() ([]byte, error) {
+	return json.Marshal(section)
+}
+
+// BufferOpenedSection records the course for the next digest if it opened since it was previously scraped
+func BufferOpenedSection(ctx context.Context, previous Course, course Course) error {
+	if previous.OpenSection || !course.OpenSection {
+		return nil
+	}
+
+	pipe := kv.Pipeline()
+	pipe.RPush(ctx, DigestKey(), OpenedSection{
+		CourseReferenceNumber: course.CourseReferenceNumber,
+		Subject:               course.Subject,
+		CourseNumber:          course.CourseNumber,
+		SequenceNumber:        course.SequenceNumber,
+		Title:                 course.CourseTitle,
+		SeatsAvailable:        course.SeatsAvailable,
+		Time:                  time.Now(),
+	})
+	pipe.LTrim(ctx, DigestKey(), -MaxDigestSize, -1)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to buffer opened section: %w", err)
+	}
+
+	return nil
+}
+
+// takeOpenedSections atomically removes & returns the buffered opened sections, so each is only posted once across instances.
+// Sections opened several times since the last digest are only returned once.
+func takeOpenedSections(ctx context.Context) ([]OpenedSection, error) {
+	pipe := kv.TxPipeline()
+	entries := pipe.LRange(ctx, DigestKey(), 0, -1)
+	pipe.Del(ctx, DigestKey())
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take opened sections: %w", err)
+	}
+
+	sections := []OpenedSection{}
+	for _, entry := range entries.Val() {
+		var section OpenedSection
+		err := json.Unmarshal([]byte(entry), &section)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("entry", entry).Msg("Failed to unmarshal opened section")
+			continue
+		}
+		sections = append(sections, section)
+	}
+
+	return lo.UniqBy(sections, func(section OpenedSection) string {
+		return section.CourseReferenceNumber
+	}), nil
+}
+
+// SetDigestSubjects stores the subjects a guild receives digests for, tracking which guilds have digests enabled
+func SetDigestSubjects(ctx context.Context, guildID string, config *GuildConfig, subjects []string) error {
+	config.DigestSubjects = subjects
+
+	var err error
+	if len(subjects) == 0 {
+		err = kv.SRem(ctx, DigestGuildsKey(), guildID).Err()
+	} else {
+		err = kv.SAdd(ctx, DigestGuildsKey(), guildID).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update digest guilds: %w", err)
+	}
+
+	return nil
+}
+
+// FlushDigest posts the sections opened since the last digest to the notification channel of each guild watching their subjects
+func FlushDigest(ctx context.Context, session *discordgo.Session) error {
+	sections, err := takeOpenedSections(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(sections) == 0 {
+		return nil
+	}
+
+	guildIDs, err := kv.SMembers(ctx, DigestGuildsKey()).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get digest guilds: %w", err)
+	}
+
+	for _, guildID := range guildIDs {
+		config, err := GetGuildConfig(ctx, guildID)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("guildID", guildID).Msg("Failed to get guild config for digest")
+			continue
+		}
+
+		if config.NotificationChannel == "" {
+			continue
+		}
+
+		watched := lo.Filter(sections, func(section OpenedSection, _ int) bool {
+			return lo.Contains(config.DigestSubjects, section.Subject)
+		})
+		if len(watched) == 0 {
+			continue
+		}
+
+		_, err = session.ChannelMessageSendEmbed(config.NotificationChannel, digestEmbed(watched))
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("guildID", guildID).Str("channelID", config.NotificationChannel).Msg("Failed to send digest")
+		}
+	}
+
+	log.Ctx(ctx).Debug().Int("sections", len(sections)).Int("guilds", len(guildIDs)).Msg("Digest flushed")
+	return nil
+}
+
+// digestEmbed lists the opened sections, truncated to stay within the description limit
+func digestEmbed(sections []OpenedSection) *discordgo.MessageEmbed {
+	var description strings.Builder
+	for index, section := range sections {
+		line := fmt.Sprintf("`%s` **%s %s-%s** %s (%d open)\n", section.CourseReferenceNumber, section.Subject, section.CourseNumber, section.SequenceNumber, EscapeMarkdown(section.Title), section.SeatsAvailable)
+		if description.Len()+len(line) > 3500 {
+			fmt.Fprintf(&description, "…and %d more\n", len(sections)-index)
+			break
+		}
+		description.WriteString(line)
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Newly Opened Sections (%d)", len(sections)),
+		Description: description.String(),
+		Footer:      GetFetchedFooter(time.Now()),
+		Color:       0x2ECC71,
+	}
+}
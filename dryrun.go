@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// dryRunDefaults are the responses for endpoints without a fixture, shaped so callers parse them as empty results
+var dryRunDefaults = map[string]string{
+	"searchResults": `{"success":true,"totalCount":0,"pageOffset":0,"pageMaxSize":0,"data":[]}`,
+	"getTerms":      `[]`,
+	"get_subject":   `[]`,
+}
+
+// DryRunResponse builds the canned response returned instead of sending a request in dry-run mode (DRY_RUN).
+// Responses are read from the fixtures directory by the last segment of the request path (e.g. searchResults => search/searchResults.json),
+// falling back to an empty response.
+func DryRunResponse(req *http.Request) *http.Response {
+	endpoint := path.Base(req.URL.Path)
+	body := dryRunDefaults[endpoint]
+	if body == "" {
+		body = "{}"
+	}
+	contentType := JsonContentType
+
+	// Fixtures may be kept at the top level, or grouped into subdirectories
+	matches, _ := filepath.Glob(filepath.Join(dryRunFixturesDir, endpoint+".*"))
+	nested, _ := filepath.Glob(filepath.Join(dryRunFixturesDir, "*", endpoint+".*"))
+	matches = append(matches, nested...)
+	if len(matches) > 0 {
+		raw, err := os.ReadFile(matches[0])
+		if err != nil {
+			log.Warn().Err(err).Str("path", matches[0]).Msg("Cannot read dry-run fixture, using an empty response")
+		} else {
+			body = string(raw)
+			if strings.HasSuffix(matches[0], ".html") {
+				contentType = "text/html;charset=UTF-8"
+			}
+		}
+	}
+
+	log.Ctx(req.Context()).Info().Str("method", req.Method).Str("url", req.URL.String()).Int("length", len(body)).Msg("Dry run, request not sent")
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {contentType}},
+		Body:          io.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+)
+
+// IndexSubject records a course's CRN under its subject, so a subject's cached sections can be found without scanning
+func IndexSubject(ctx context.Context, course Course) error {
+	if course.Subject == "" {
+		return nil
+	}
+	return kv.SAdd(ctx, SubjectKey(course.Term, course.Subject), course.CourseReferenceNumber).Err()
+}
+
+// GetSubjectCourses returns the scraped courses indexed under the subject within the term.
+// Courses that have since expired or moved to another term are skipped.
+func GetSubjectCourses(ctx context.Context, term string, subject string) ([]Course, error) {
+	crns, err := kv.SMembers(ctx, SubjectKey(term, subject)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subject index: %w", err)
+	}
+
+	if len(crns) == 0 {
+		return nil, nil
+	}
+
+	values, err := kv.MGet(ctx, lo.Map(crns, func(crn string, _ int) string {
+		return ClassKey(crn)
+	})...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subject courses: %w", err)
+	}
+
+	courses := make([]Course, 0, len(values))
+	for index, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var course Course
+		err = json.Unmarshal([]byte(raw), &course)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("crn", crns[index]).Msg("Failed to unmarshal indexed course")
+			continue
+		}
+
+		if course.Term != term {
+			continue
+		}
+		courses = append(courses, course)
+	}
+
+	return courses, nil
+}
+
+// EnrollmentStats summarizes the enrollment of a group of sections
+type EnrollmentStats struct {
+	Sections int
+	Capacity int
+	Enrolled int
+	// Sections with a capacity, ordered from most to least full
+	ByFill []Course
+}
+
+// FillRate returns the fraction of a section's seats taken, which may exceed 1 when over-enrolled
+func FillRate(course Course) float64 {
+	if course.MaximumEnrollment <= 0 {
+		return 0
+	}
+	return float64(course.Enrollment) / float64(course.MaximumEnrollment)
+}
+
+// ComputeEnrollmentStats totals the capacity & enrollment of the given sections
+func ComputeEnrollmentStats(courses []Course) EnrollmentStats {
+	stats := EnrollmentStats{Sections: len(courses)}
+	for _, course := range courses {
+		stats.Capacity += course.MaximumEnrollment
+		stats.Enrolled += course.Enrollment
+	}
+
+	// Sections without a capacity (e.g. independent study) have no meaningful fill rate
+	stats.ByFill = lo.Filter(courses, func(course Course, _ int) bool {
+		return course.MaximumEnrollment > 0
+	})
+	sort.SliceStable(stats.ByFill, func(a, b int) bool {
+		return FillRate(stats.ByFill[a]) > FillRate(stats.ByFill[b])
+	})
+
+	return stats
+}
+
+// FillPercent returns the overall percentage of seats taken
+func (s EnrollmentStats) FillPercent() float64 {
+	if s.Capacity <= 0 {
+		return 0
+	}
+	return float64(s.Enrolled) * 100 / float64(s.Capacity)
+}
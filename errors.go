@@ -1,6 +1,10 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
 
 type UnexpectedContentTypeError struct {
 	Expected string
@@ -10,3 +14,65 @@ type UnexpectedContentTypeError struct {
 func (e *UnexpectedContentTypeError) Error() string {
 	return fmt.Sprintf("Expected content type '%s', received '%s'", e.Expected, e.Actual)
 }
+
+// CourseNotFoundError is returned when a CRN does not exist within the term, as opposed to existing without any meetings
+type CourseNotFoundError struct {
+	Term string
+	CRN  string
+}
+
+func (e *CourseNotFoundError) Error() string {
+	return fmt.Sprintf("course %s not found in term %s", e.CRN, e.Term)
+}
+
+// UserError is an error caused by invalid user input. Its message is shown to the user as-is.
+type UserError struct {
+	Message string
+}
+
+// NewUserError creates a UserError with a formatted message
+func NewUserError(format string, a ...interface{}) *UserError {
+	return &UserError{Message: fmt.Sprintf(format, a...)}
+}
+
+func (e *UserError) Error() string {
+	return e.Message
+}
+
+// knownPanics maps substrings of known panic causes to user-facing explanations.
+// Most of these originate from the parsing helpers on MeetingTimeResponse, which panic on malformed Banner data.
+var knownPanics = []struct {
+	Match   string
+	Message string
+}{
+	{"Cannot parse start date", "This section has unparseable schedule data from Banner."},
+	{"Cannot parse end date", "This section has unparseable schedule data from Banner."},
+	{"Start time is empty", "This section has no meeting time listed in Banner."},
+	{"Cannot parse start time integer", "This section has unparseable meeting time data from Banner."},
+	{"Cannot parse end time integer", "This section has unparseable meeting time data from Banner."},
+	{"index out of range", "Banner returned incomplete data for this request."},
+}
+
+// PanicMessage returns a user-facing explanation for a recovered panic value.
+// Unknown panic causes receive a generic message.
+func PanicMessage(detail interface{}) string {
+	var raw string
+	switch value := detail.(type) {
+	case string:
+		raw = value
+	case runtime.Error:
+		raw = value.Error()
+	case error:
+		raw = value.Error()
+	default:
+		raw = fmt.Sprintf("%v", value)
+	}
+
+	for _, known := range knownPanics {
+		if strings.Contains(raw, known.Match) {
+			return known.Message
+		}
+	}
+
+	return "Unexpected Error: command handler panic"
+}
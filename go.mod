@@ -5,6 +5,7 @@ go 1.21
 require github.com/bwmarrin/discordgo v0.27.1
 
 require (
+	github.com/cespare/xxhash/v2 v2.2.0
 	github.com/joho/godotenv v1.5.1
 	github.com/pkg/errors v0.9.1
 	github.com/redis/go-redis/v9 v9.3.1
@@ -15,7 +16,6 @@ require (
 
 require (
 	github.com/arran4/golang-ical v0.2.3 // indirect
-	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/cnf/structhash v0.0.0-20201127153200-e1b16c1ebc08 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GuildConfig holds the per-guild options set with the /config command
+type GuildConfig struct {
+	// The subject searched by /search when none is provided (e.g. CS)
+	DefaultSubject string `json:"defaultSubject,omitempty"`
+	// The channel ID that notifications are sent to
+	NotificationChannel string `json:"notificationChannel,omitempty"`
+	// The subjects whose newly opened sections are posted to the notification channel as a digest
+	DigestSubjects []string `json:"digestSubjects,omitempty"`
+}
+
+func (config GuildConfig) MarshalBinary() ([]byte, error) {
+	return json.Marshal(config)
+}
+
+// GetGuildConfig retrieves the configuration of the given guild.
+// Guilds without a stored configuration (and DMs) receive an empty configuration.
+func GetGuildConfig(ctx context.Context, guildID string) (*GuildConfig, error) {
+	config := &GuildConfig{}
+	if guildID == "" {
+		return config, nil
+	}
+
+	raw, err := kv.Get(ctx, GuildConfigKey(guildID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return config, nil
+		}
+		return nil, fmt.Errorf("failed to get guild config: %w", err)
+	}
+
+	err = json.Unmarshal([]byte(raw), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal guild config: %w", err)
+	}
+
+	return config, nil
+}
+
+// SetGuildConfig stores the configuration of the given guild
+func SetGuildConfig(ctx context.Context, guildID string, config *GuildConfig) error {
+	err := kv.Set(ctx, GuildConfigKey(guildID), config, 0).Err()
+	if err != nil {
+		return fmt.Errorf("failed to store guild config: %w", err)
+	}
+	return nil
+}
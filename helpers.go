@@ -1,16 +1,20 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -20,8 +24,9 @@ import (
 	"github.com/samber/lo"
 )
 
-// BuildRequestWithBody builds a request with the given method, path, parameters, and body
-func BuildRequestWithBody(method string, path string, params map[string]string, body io.Reader) *http.Request {
+// BuildRequestWithBody builds a request with the given method, path, parameters, and body.
+// The request is bound to the given context, so cancelling it aborts the request.
+func BuildRequestWithBody(ctx context.Context, method string, path string, params map[string]string, body io.Reader) *http.Request {
 	// Builds a URL for the given path and parameters
 	requestUrl := baseURL + path
 
@@ -38,14 +43,14 @@ func BuildRequestWithBody(method string, path string, params map[string]string,
 		}
 	}
 
-	request, _ := http.NewRequest(method, requestUrl, body)
+	request, _ := http.NewRequestWithContext(ctx, method, requestUrl, body)
 	AddUserAgent(request)
 	return request
 }
 
 // BuildRequest builds a request with the given method, path, and parameters and an empty body
-func BuildRequest(method string, path string, params map[string]string) *http.Request {
-	return BuildRequestWithBody(method, path, params, nil)
+func BuildRequest(ctx context.Context, method string, path string, params map[string]string) *http.Request {
+	return BuildRequestWithBody(ctx, method, path, params, nil)
 }
 
 // AddUserAgent adds a false but consistent user agent to the request
@@ -112,8 +117,27 @@ func Nonce() string {
 	return strconv.Itoa(int(time.Now().UnixMilli()))
 }
 
+// bannerAPIPaths are the endpoint groups (relative to bannerPathPrefix) that count as session activity
+var bannerAPIPaths = []string{"/classSearch/", "/searchResults/"}
+
+// IsBannerAPIPath checks if the given absolute request path targets one of the Banner API endpoints
+func IsBannerAPIPath(path string) bool {
+	relative, found := strings.CutPrefix(path, bannerPathPrefix)
+	if !found {
+		return false
+	}
+
+	return lo.SomeBy(bannerAPIPaths, func(prefix string) bool {
+		return strings.HasPrefix(relative, prefix)
+	})
+}
+
 // DoRequest performs & logs the request, logging and returning the response
 func DoRequest(req *http.Request) (*http.Response, error) {
+	if dryRun {
+		return DryRunResponse(req), nil
+	}
+
 	headerSize := 0
 	for key, values := range req.Header {
 		for _, value := range values {
@@ -122,14 +146,23 @@ func DoRequest(req *http.Request) (*http.Response, error) {
 		}
 	}
 
-	bodySize := int64(0)
-	if req.Body != nil {
-		bodySize, _ = io.Copy(io.Discard, req.Body)
+	// The body is not read here, as it still needs to be sent
+	bodySize := req.ContentLength
+
+	// Use the logger scoped to the request's context, if any
+	logger := log.Ctx(req.Context())
+
+	// Sample the high-frequency request & response logs together, so each logged request keeps its response.
+	// Failures are always logged with the unsampled logger.
+	sampledLogger := logger
+	if !requestLogSampler.Sample(zerolog.DebugLevel) {
+		disabled := logger.Level(zerolog.Disabled)
+		sampledLogger = &disabled
 	}
 
 	size := zerolog.Dict().Int64("body", bodySize).Int("header", headerSize).Int("url", len(req.URL.String()))
 
-	log.Debug().
+	sampledLogger.Debug().
 		Dict("size", size).
 		Str("method", strings.TrimRight(req.Method, " ")).
 		Str("url", req.URL.String()).
@@ -140,13 +173,18 @@ func DoRequest(req *http.Request) (*http.Response, error) {
 	res, err := client.Do(req)
 
 	if err != nil {
-		log.Err(err).Stack().Str("method", req.Method).Msg("Request Failed")
+		// Timeouts are common when Banner is under load, so make them clearly identifiable
+		if os.IsTimeout(err) {
+			err = fmt.Errorf("banner did not respond within %s: %w", requestTimeout, err)
+		}
+
+		logger.Err(err).Stack().Str("method", req.Method).Msg("Request Failed")
 	} else {
 		contentLengthHeader := res.Header.Get("Content-Length")
 		contentLength := int64(-1)
 
 		// If this request was a Banner API request, reset the session timer
-		if strings.HasPrefix(req.URL.Path, "StudentRegistrationSsb/ssb/classSearch/") {
+		if IsBannerAPIPath(req.URL.Path) {
 			ResetSessionTimer()
 		}
 
@@ -158,28 +196,11 @@ func DoRequest(req *http.Request) (*http.Response, error) {
 			}
 		}
 
-		log.Debug().Int("status", res.StatusCode).Int64("content-length", contentLength).Strs("content-type", res.Header["Content-Type"]).Msg("Response")
+		sampledLogger.Debug().Int("status", res.StatusCode).Int64("content-length", contentLength).Strs("content-type", res.Header["Content-Type"]).Msg("Response")
 	}
 	return res, err
 }
 
-// Plural is a simple helper function that returns an empty string if n is 1, and "s" otherwise.
-func Plural(n int) string {
-	if n == 1 {
-		return ""
-	}
-	return "s"
-}
-
-// Plurale is a simple helper function that returns an empty string if n is 1, and "ess" otherwise.
-// This is for words that end in "es" when plural.
-func Plurale(n int) string {
-	if n == 1 {
-		return ""
-	}
-	return "es"
-}
-
 func WeekdaysToString(days map[time.Weekday]bool) string {
 	// If no days are present
 	numDays := len(days)
@@ -230,8 +251,60 @@ type NaiveTime struct {
 	Minutes uint
 }
 
+// Sub returns the duration from the other time to this time, which is negative if this time is earlier
 func (nt *NaiveTime) Sub(other *NaiveTime) time.Duration {
-	return time.Hour*time.Duration(nt.Hours-other.Hours) + time.Minute*time.Duration(nt.Minutes-other.Minutes)
+	return time.Minute * time.Duration(nt.TotalMinutes()-other.TotalMinutes())
+}
+
+// Valid checks if the time is a real time of day (e.g. not 25:70)
+func (nt NaiveTime) Valid() bool {
+	return nt.Hours < 24 && nt.Minutes < 60
+}
+
+// TotalMinutes returns the number of minutes since midnight
+func (nt NaiveTime) TotalMinutes() int {
+	return int(nt.Hours)*60 + int(nt.Minutes)
+}
+
+// Add returns the time after the given duration, wrapping around midnight
+func (nt NaiveTime) Add(d time.Duration) NaiveTime {
+	const day = 24 * 60
+	minutes := (nt.TotalMinutes() + int(d/time.Minute)) % day
+	if minutes < 0 {
+		minutes += day
+	}
+
+	return NaiveTime{Hours: uint(minutes / 60), Minutes: uint(minutes % 60)}
+}
+
+// Before reports whether the time is before the other
+func (nt NaiveTime) Before(other NaiveTime) bool {
+	return nt.TotalMinutes() < other.TotalMinutes()
+}
+
+// After reports whether the time is after the other
+func (nt NaiveTime) After(other NaiveTime) bool {
+	return nt.TotalMinutes() > other.TotalMinutes()
+}
+
+// Equal reports whether the times are the same
+func (nt NaiveTime) Equal(other NaiveTime) bool {
+	return nt.TotalMinutes() == other.TotalMinutes()
+}
+
+// FormatDuration formats a duration as hours & minutes for display (e.g. "1h 15m", "50m", "2h")
+func FormatDuration(d time.Duration) string {
+	hours := int64(d / time.Hour)
+	minutes := int64(d%time.Hour) / int64(time.Minute)
+
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
 }
 
 func ParseNaiveTime(integer uint64) *NaiveTime {
@@ -244,7 +317,9 @@ func ParseNaiveTime(integer uint64) *NaiveTime {
 func (nt NaiveTime) String() string {
 	meridiem := "AM"
 	hour := nt.Hours
-	if nt.Hours >= 12 {
+	if nt.Hours == 0 {
+		hour = 12
+	} else if nt.Hours >= 12 {
 		meridiem = "PM"
 		if nt.Hours > 12 {
 			hour -= 12
@@ -253,15 +328,84 @@ func (nt NaiveTime) String() string {
 	return fmt.Sprintf("%d:%02d%s", hour, nt.Minutes, meridiem)
 }
 
+// GetFirstEnv returns the value of the first environment variable set among the keys.
+// Each key may instead point to a file holding the value with a _FILE suffix (e.g. BOT_TOKEN_FILE), as with mounted secrets.
 func GetFirstEnv(key ...string) string {
 	for _, k := range key {
 		if v := os.Getenv(k); v != "" {
 			return v
 		}
+
+		if path := os.Getenv(k + "_FILE"); path != "" {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				log.Warn().Err(err).Str("key", k+"_FILE").Str("path", path).Msg("Cannot read secret file")
+				continue
+			}
+
+			if v := strings.TrimSpace(string(raw)); v != "" {
+				return v
+			}
+		}
 	}
 	return ""
 }
 
+// GetListEnv splits a comma separated environment variable into its trimmed, non-empty values
+func GetListEnv(key string) []string {
+	values := []string{}
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// GetDurationEnv parses a duration (e.g. "90s", "2m") from the given environment variable.
+// If the variable is unset, negative, or invalid, the fallback is returned.
+func GetDurationEnv(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil || value < 0 {
+		log.Warn().Err(err).Str("key", key).Str("value", raw).Msg("Invalid duration in environment, using default")
+		return fallback
+	}
+
+	return value
+}
+
+// ValidateBaseURL checks that the given URL is a well-formed, absolute HTTPS URL without a query or fragment
+func ValidateBaseURL(raw string) error {
+	if raw == "" {
+		return fmt.Errorf("base URL is not set")
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("base URL is malformed: %w", err)
+	}
+
+	if !parsed.IsAbs() || parsed.Host == "" {
+		return fmt.Errorf("base URL must be absolute (%s)", raw)
+	}
+
+	if parsed.Scheme != "https" {
+		return fmt.Errorf("base URL must use https, not %s", parsed.Scheme)
+	}
+
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("base URL must not contain a query or fragment (%s)", raw)
+	}
+
+	return nil
+}
+
 // GetIntPointer returns a pointer to the given value.
 // This function is useful for discordgo, which inexplicably requires pointers to integers for minLength arguments.
 func GetIntPointer(value int) *int {
@@ -274,6 +418,18 @@ func GetFloatPointer(value float64) *float64 {
 	return &value
 }
 
+// GetInt64Pointer returns a pointer to the given value.
+// This function is useful for discordgo, which requires pointers to integers for default member permissions.
+func GetInt64Pointer(value int64) *int64 {
+	return &value
+}
+
+// GetBoolPointer returns a pointer to the given value.
+// This function is useful for discordgo, which requires pointers to booleans for DM permissions.
+func GetBoolPointer(value bool) *bool {
+	return &value
+}
+
 var extensionMap = map[string]string{
 	"text/plain":               "txt",
 	"application/json":         "json",
@@ -311,13 +467,19 @@ func GuessExtension(contentType string) string {
 	return ext
 }
 
-// DumpResponse dumps a response body to a file for debugging purposes
+// DumpResponse dumps a response body to a file in dumpsDir for debugging purposes, pruning old dumps afterwards
 func DumpResponse(res *http.Response) {
 	contentType := res.Header.Get("Content-Type")
 	ext := GuessExtension(contentType)
 
-	// Use current time as filename + /dumps/ prefix
-	filename := fmt.Sprintf("dumps/%d.%s", time.Now().Unix(), ext)
+	err := os.MkdirAll(dumpsDir, 0o755)
+	if err != nil {
+		log.Err(err).Stack().Str("directory", dumpsDir).Msg("Error creating dumps directory")
+		return
+	}
+
+	// Use current time as filename
+	filename := filepath.Join(dumpsDir, fmt.Sprintf("%d.%s", time.Now().UnixMilli(), ext))
 	file, err := os.Create(filename)
 
 	if err != nil {
@@ -333,33 +495,300 @@ func DumpResponse(res *http.Response) {
 	}
 
 	log.Info().Str("filename", filename).Str("content-type", contentType).Msg("Dumped response body")
+
+	err = PruneDumps()
+	if err != nil {
+		log.Err(err).Stack().Msg("Error pruning dumps")
+	}
 }
 
-// ResponseError responds to an interaction with an error message
-// TODO: Improve with a proper embed and colors
-func RespondError(session *discordgo.Session, interaction *discordgo.Interaction, message string, err error) error {
+// PruneDumps deletes dumps older than dumpsMaxAge, then the oldest dumps beyond dumpsMaxCount
+func PruneDumps() error {
+	entries, err := os.ReadDir(dumpsDir)
+	if err != nil {
+		return fmt.Errorf("failed to read dumps directory: %w", err)
+	}
+
+	type dump struct {
+		path    string
+		modTime time.Time
+	}
+
+	dumps := []dump{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		dumps = append(dumps, dump{path: filepath.Join(dumpsDir, entry.Name()), modTime: info.ModTime()})
+	}
+
+	// Newest first, so everything past the count limit is the oldest
+	sort.Slice(dumps, func(i, j int) bool {
+		return dumps[i].modTime.After(dumps[j].modTime)
+	})
+
+	for index, dump := range dumps {
+		expired := dumpsMaxAge > 0 && time.Since(dump.modTime) > dumpsMaxAge
+		excess := dumpsMaxCount > 0 && index >= dumpsMaxCount
+		if !expired && !excess {
+			continue
+		}
+
+		err := os.Remove(dump.path)
+		if err != nil {
+			return fmt.Errorf("failed to remove dump: %w", err)
+		}
+		log.Debug().Str("filename", dump.path).Msg("Pruned dump")
+	}
+
+	return nil
+}
+
+// ErrorLevel describes the severity of an error shown to a user, controlling the color & visibility of the response
+type ErrorLevel int
+
+const (
+	// ErrorLevelUser is for errors caused by invalid user input; shown only to the user
+	ErrorLevelUser ErrorLevel = iota
+	// ErrorLevelUpstream is for errors caused by the Banner system (outages, unexpected responses)
+	ErrorLevelUpstream
+	// ErrorLevelInternal is for errors caused by the bot itself (bugs, panics)
+	ErrorLevelInternal
+)
+
+// Color returns the embed color for the error level
+func (level ErrorLevel) Color() int {
+	switch level {
+	case ErrorLevelUser:
+		return 0xFFCC00
+	case ErrorLevelUpstream:
+		return 0xFF6500
+	default:
+		return 0xFF0000
+	}
+}
+
+// Ephemeral returns true if errors of this level should only be visible to the invoking user
+func (level ErrorLevel) Ephemeral() bool {
+	return level == ErrorLevelUser
+}
+
+type responseStateKey struct{}
+
+// responseState tracks how an interaction has been acknowledged, so responses can be sent the correct way
+type responseState struct {
+	deferred bool
+}
+
+// WithResponseState returns a copy of the context able to track whether the interaction's response was deferred
+func WithResponseState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, responseStateKey{}, &responseState{})
+}
+
+// IsDeferred returns true if the interaction tracked by the context has been deferred
+func IsDeferred(ctx context.Context) bool {
+	state, ok := ctx.Value(responseStateKey{}).(*responseState)
+	return ok && state.deferred
+}
+
+// DeferResponse acknowledges the interaction without responding, allowing up to 15 minutes for the real response.
+// This should be used by commands that may take longer than Discord's 3 second response deadline.
+// Once deferred, responses made with Respond edit the deferred response instead.
+func DeferResponse(ctx context.Context, session *discordgo.Session, interaction *discordgo.Interaction) error {
+	err := RetryDiscord(ctx, func() error {
+		return session.InteractionRespond(interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if state, ok := ctx.Value(responseStateKey{}).(*responseState); ok {
+		state.deferred = true
+	}
+	return nil
+}
+
+// IsTransientDiscordError returns true if the error is a temporary Discord failure (rate limit, server error, network timeout) worth retrying.
+// If Discord specified how long to wait, it is returned as well.
+func IsTransientDiscordError(err error) (bool, time.Duration) {
+	var rateLimitErr *discordgo.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return true, rateLimitErr.RetryAfter
+	}
+
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Response != nil {
+		status := restErr.Response.StatusCode
+		return status == http.StatusTooManyRequests || status >= 500, 0
+	}
+
+	return os.IsTimeout(err), 0
+}
+
+// RetryDiscord invokes the Discord API call, retrying with backoff on transient failures.
+// Non-transient failures (e.g. invalid requests, missing permissions) are returned immediately.
+func RetryDiscord(ctx context.Context, call func() error) error {
+	const attempts = 3
+	backoff := 500 * time.Millisecond
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = call()
+		if err == nil {
+			return nil
+		}
+
+		transient, retryAfter := IsTransientDiscordError(err)
+		if !transient || attempt == attempts {
+			return err
+		}
+
+		wait := max(backoff, retryAfter)
+		log.Ctx(ctx).Warn().Err(err).Int("attempt", attempt).Dur("wait", wait).Msg("Transient Discord failure, retrying")
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+
+	return err
+}
+
+// Respond responds to an interaction with the given data, editing the response instead if it was deferred.
+// Flags cannot be changed when editing, so a deferred ephemeral response replaces the public placeholder with an ephemeral followup.
+// Transient failures are retried, unless files are attached (their readers cannot be re-read).
+func Respond(ctx context.Context, session *discordgo.Session, interaction *discordgo.Interaction, data *discordgo.InteractionResponseData) error {
+	retry := RetryDiscord
+	if len(data.Files) > 0 {
+		retry = func(_ context.Context, call func() error) error {
+			return call()
+		}
+	}
+
+	if !IsDeferred(ctx) {
+		return retry(ctx, func() error {
+			return session.InteractionRespond(interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: data,
+			})
+		})
+	}
+
+	if data.Flags&discordgo.MessageFlagsEphemeral != 0 {
+		// The first followup would replace the placeholder (and its visibility), so it must be removed beforehand
+		if err := session.InteractionResponseDelete(interaction); err != nil {
+			log.Warn().Err(err).Msg("Failed to delete deferred response")
+		}
+
+		params := &discordgo.WebhookParams{
+			Content:         data.Content,
+			Embeds:          data.Embeds,
+			Components:      data.Components,
+			Files:           data.Files,
+			AllowedMentions: data.AllowedMentions,
+			Flags:           discordgo.MessageFlagsEphemeral,
+		}
+		return retry(ctx, func() error {
+			_, err := session.FollowupMessageCreate(interaction, true, params)
+			return err
+		})
+	}
+
+	edit := &discordgo.WebhookEdit{
+		Files:           data.Files,
+		AllowedMentions: data.AllowedMentions,
+	}
+	if data.Content != "" {
+		edit.Content = &data.Content
+	}
+	if data.Embeds != nil {
+		edit.Embeds = &data.Embeds
+	}
+	if data.Components != nil {
+		edit.Components = &data.Components
+	}
+
+	return retry(ctx, func() error {
+		_, err := session.InteractionResponseEdit(interaction, edit)
+		return err
+	})
+}
+
+// RespondError responds to an interaction with an internal error message
+func RespondError(ctx context.Context, session *discordgo.Session, interaction *discordgo.Interaction, message string, err error) error {
+	return RespondErrorWithLevel(ctx, session, interaction, ErrorLevelInternal, message, err)
+}
+
+// RespondErrorWithLevel responds to an interaction with an error message, styled according to the error level.
+// If the context carries a request ID, it is shown in the footer so users can reference it when reporting issues.
+func RespondErrorWithLevel(ctx context.Context, session *discordgo.Session, interaction *discordgo.Interaction, level ErrorLevel, message string, err error) error {
 	// Optional: log the error
 	if err != nil {
-		log.Err(err).Stack().Msg(message)
-	}
-
-	return session.InteractionRespond(interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{
-				{
-					Footer: &discordgo.MessageEmbedFooter{
-						Text: fmt.Sprintf("Occurred at %s", time.Now().Format("Monday, January 2, 2006 at 3:04:05PM")),
-					},
-					Description: message,
-					Color:       0xff0000,
+		log.Ctx(ctx).Err(err).Stack().Int("errorLevel", int(level)).Msg(message)
+	}
+
+	var flags discordgo.MessageFlags
+	if level.Ephemeral() {
+		flags = discordgo.MessageFlagsEphemeral
+	}
+
+	footer := fmt.Sprintf("Occurred at %s", time.Now().In(CentralTimeLocation).Format("Monday, January 2, 2006 at 3:04:05PM"))
+	if requestID := RequestID(ctx); requestID != "" {
+		footer = fmt.Sprintf("Error ID %s • %s", requestID, footer)
+	}
+
+	return Respond(ctx, session, interaction, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Footer: &discordgo.MessageEmbedFooter{
+					Text: footer,
 				},
+				Description: message,
+				Color:       level.Color(),
 			},
-			AllowedMentions: &discordgo.MessageAllowedMentions{},
 		},
+		Flags:           flags,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
 	})
 }
 
+// markdownEscaper escapes characters that Discord interprets as markdown, and breaks up mention-like tokens
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`,
+	"[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, ">", `\>`, "#", `\#`,
+	"@", "@\u200b",
+)
+
+// EscapeMarkdown escapes user or Banner provided text so it renders literally within embeds and link text
+func EscapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// ProgressBar renders a fixed-width bar of Unicode blocks showing how much of total is filled (e.g. "▓▓▓▓░░░░░░")
+func ProgressBar(filled int, total int, width int) string {
+	if total <= 0 {
+		return strings.Repeat("░", width)
+	}
+
+	count := min(width, max(0, filled*width/total))
+	// Any fill at all should be visible
+	if count == 0 && filled > 0 {
+		count = 1
+	}
+
+	return strings.Repeat("▓", count) + strings.Repeat("░", width-count)
+}
+
 func GetFetchedFooter(time time.Time) *discordgo.MessageEmbedFooter {
 	return &discordgo.MessageEmbedFooter{
 		Text: fmt.Sprintf("Fetched at %s", time.In(CentralTimeLocation).Format("Monday, January 2, 2006 at 3:04:05PM")),
@@ -378,6 +807,25 @@ func GetUser(interaction *discordgo.InteractionCreate) *discordgo.User {
 	return interaction.User
 }
 
+// IsAdmin returns true if the user invoking the interaction is configured as an admin, either by user ID or by guild role
+func IsAdmin(interaction *discordgo.InteractionCreate) bool {
+	user := GetUser(interaction)
+	if user != nil && lo.Contains(adminUserIDs, user.ID) {
+		return true
+	}
+
+	// Roles are only available when invoked within a guild
+	if interaction.Member != nil {
+		for _, role := range interaction.Member.Roles {
+			if lo.Contains(adminRoleIDs, role) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // Encode encodes the values into “URL encoded” form
 // ("bar=baz&foo=quux") sorted by key.
 func EncodeParams(params map[string]*[]string) string {
@@ -415,43 +863,145 @@ func EncodeParams(params map[string]*[]string) string {
 	return buf.String()
 }
 
-var terms []BannerTerm
-var lastTermUpdate time.Time
+var (
+	terms           []BannerTerm
+	lastTermUpdate  time.Time
+	lastTermAttempt time.Time
+	// termsLock guards terms, lastTermUpdate & lastTermAttempt, which are read by commands while being reloaded
+	termsLock sync.RWMutex
+	// termsReloadLock allows only one reload at a time, so a burst of commands during an outage makes a single request
+	termsReloadLock sync.Mutex
+)
+
+// TermRetryInterval is how often reloading the terms is retried while only stale terms are available
+const TermRetryInterval = 5 * time.Minute
+
+// LoadedTerms returns the terms last loaded, which may be stale or empty.
+// The returned slice is replaced rather than modified on reload, and must not be modified.
+func LoadedTerms() []BannerTerm {
+	termsLock.RLock()
+	defer termsLock.RUnlock()
+	return terms
+}
+
+// LastTermUpdate returns when the terms were last fetched from Banner, or the zero time if they never were
+func LastTermUpdate() time.Time {
+	termsLock.RLock()
+	defer termsLock.RUnlock()
+	return lastTermUpdate
+}
+
+// termsReloadRequired checks if the terms are missing, or out of date and not recently attempted
+func termsReloadRequired() bool {
+	termsLock.RLock()
+	defer termsLock.RUnlock()
+	return len(terms) == 0 || (time.Since(lastTermUpdate) >= 24*time.Hour && time.Since(lastTermAttempt) >= TermRetryInterval)
+}
 
-// TryReloadTerms attempts to reload the terms if they are not loaded or the last update was more than 24 hours ago
-func TryReloadTerms() error {
-	if len(terms) > 0 && time.Since(lastTermUpdate) < 24*time.Hour {
+// TryReloadTerms attempts to reload the terms if they are not loaded or the last update was more than 24 hours ago.
+// If reloading fails while older terms are loaded, the older terms are kept and no error is returned.
+func TryReloadTerms(ctx context.Context) error {
+	if !termsReloadRequired() {
 		return nil
 	}
 
+	termsReloadLock.Lock()
+	defer termsReloadLock.Unlock()
+
+	// Another reload may have finished (or failed) while waiting
+	if !termsReloadRequired() {
+		return nil
+	}
+
+	termsLock.Lock()
+	lastTermAttempt = time.Now()
+	termsLock.Unlock()
+
 	// Load the terms
-	var err error
-	terms, err = GetTerms("", 1, 100)
+	result, err := GetTerms(ctx, "", 1, 100)
 	if err != nil {
+		// Stale terms are still better than none while Banner is unavailable
+		if len(LoadedTerms()) > 0 {
+			log.Ctx(ctx).Warn().Err(err).Time("lastUpdate", LastTermUpdate()).Msg("Failed to reload terms, using stale terms")
+			return nil
+		}
 		return errors.Wrap(err, "failed to load terms")
 	}
 
+	if result.HasMore {
+		log.Ctx(ctx).Warn().Int("count", len(result.Terms)).Msg("Term list may be incomplete, more pages are available")
+	}
+
+	termsLock.Lock()
+	terms = result.Terms
 	lastTermUpdate = time.Now()
+	termsLock.Unlock()
+
+	// Keep a copy for startups while Banner is unavailable, failure here is not fatal
+	raw, err := json.Marshal(result.Terms)
+	if err == nil {
+		err = kv.Set(ctx, TermsKey(), raw, 0).Err()
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to cache terms")
+	}
+
+	return nil
+}
+
+// LoadCachedTerms loads the terms last fetched from Banner out of Redis, for use until they can be reloaded
+func LoadCachedTerms(ctx context.Context) error {
+	raw, err := kv.Get(ctx, TermsKey()).Result()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cached terms")
+	}
+
+	var cached []BannerTerm
+	err = json.Unmarshal([]byte(raw), &cached)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse cached terms")
+	}
+
+	termsLock.Lock()
+	terms = cached
+	termsLock.Unlock()
+	log.Ctx(ctx).Info().Int("count", len(cached)).Msg("Loaded cached terms")
 	return nil
 }
 
+// FindBannerTerm finds the term with the given code among the terms available in Banner, returning nil if it does not exist
+func FindBannerTerm(ctx context.Context, code string) (*BannerTerm, error) {
+	err := TryReloadTerms(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bannerTerm, exists := lo.Find(LoadedTerms(), func(t BannerTerm) bool {
+		return t.Code == code
+	})
+	if !exists {
+		return nil, nil
+	}
+	return &bannerTerm, nil
+}
+
 // IsTermArchived checks if the given term is archived
 // TODO: Add error, switch missing term logic to error
-func IsTermArchived(term string) bool {
+func IsTermArchived(ctx context.Context, term string) bool {
 	// Ensure the terms are loaded
-	err := TryReloadTerms()
+	err := TryReloadTerms(ctx)
 	if err != nil {
-		log.Err(err).Stack().Msg("Failed to reload terms")
+		log.Ctx(ctx).Err(err).Stack().Msg("Failed to reload terms")
 		return true
 	}
 
 	// Check if the term is in the list of terms
-	bannerTerm, exists := lo.Find(terms, func(t BannerTerm) bool {
+	bannerTerm, exists := lo.Find(LoadedTerms(), func(t BannerTerm) bool {
 		return t.Code == term
 	})
 
 	if !exists {
-		log.Warn().Str("term", term).Msg("Term does not exist")
+		log.Ctx(ctx).Warn().Str("term", term).Msg("Term does not exist")
 		return true
 	}
 
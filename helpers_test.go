@@ -0,0 +1,119 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNaiveTime(t *testing.T) {
+	cases := []struct {
+		integer uint64
+		want    NaiveTime
+		valid   bool
+	}{
+		{0, NaiveTime{0, 0}, true},
+		{930, NaiveTime{9, 30}, true},
+		{1445, NaiveTime{14, 45}, true},
+		{2359, NaiveTime{23, 59}, true},
+		{2400, NaiveTime{24, 0}, false},
+		{1275, NaiveTime{12, 75}, false},
+	}
+
+	for _, c := range cases {
+		got := ParseNaiveTime(c.integer)
+		if *got != c.want {
+			t.Errorf("ParseNaiveTime(%d) = %+v, want %+v", c.integer, *got, c.want)
+		}
+		if got.Valid() != c.valid {
+			t.Errorf("ParseNaiveTime(%d).Valid() = %t, want %t", c.integer, got.Valid(), c.valid)
+		}
+	}
+}
+
+func TestNaiveTimeString(t *testing.T) {
+	cases := map[NaiveTime]string{
+		{0, 0}:   "12:00AM",
+		{0, 30}:  "12:30AM",
+		{9, 5}:   "9:05AM",
+		{11, 59}: "11:59AM",
+		{12, 0}:  "12:00PM",
+		{13, 15}: "1:15PM",
+		{23, 45}: "11:45PM",
+	}
+
+	for nt, want := range cases {
+		if got := nt.String(); got != want {
+			t.Errorf("%+v.String() = %q, want %q", nt, got, want)
+		}
+	}
+}
+
+func TestNaiveTimeArithmetic(t *testing.T) {
+	start := NaiveTime{9, 30}
+	end := NaiveTime{10, 45}
+
+	if got := start.TotalMinutes(); got != 570 {
+		t.Errorf("TotalMinutes() = %d, want 570", got)
+	}
+	if got := end.Sub(&start); got != 75*time.Minute {
+		t.Errorf("Sub() = %s, want 1h15m", got)
+	}
+	if got := start.Sub(&end); got != -75*time.Minute {
+		t.Errorf("Sub() = %s, want -1h15m", got)
+	}
+
+	adds := []struct {
+		from NaiveTime
+		d    time.Duration
+		want NaiveTime
+	}{
+		{start, 75 * time.Minute, end},
+		{start, 0, start},
+		{start, -90 * time.Minute, NaiveTime{8, 0}},
+		{NaiveTime{23, 30}, time.Hour, NaiveTime{0, 30}},
+		{NaiveTime{0, 15}, -30 * time.Minute, NaiveTime{23, 45}},
+		{start, 48 * time.Hour, start},
+		// Seconds are truncated, as naive times are only minute precise
+		{start, 90 * time.Second, NaiveTime{9, 31}},
+	}
+
+	for _, c := range adds {
+		if got := c.from.Add(c.d); got != c.want {
+			t.Errorf("%s.Add(%s) = %s, want %s", c.from, c.d, got, c.want)
+		}
+	}
+}
+
+func TestNaiveTimeComparison(t *testing.T) {
+	early := NaiveTime{8, 0}
+	late := NaiveTime{17, 30}
+
+	if !early.Before(late) || early.After(late) {
+		t.Errorf("expected %s to be before %s", early, late)
+	}
+	if !late.After(early) || late.Before(early) {
+		t.Errorf("expected %s to be after %s", late, early)
+	}
+	if early.Before(early) || early.After(early) || !early.Equal(early) {
+		t.Errorf("expected %s to equal itself", early)
+	}
+	if early.Equal(late) {
+		t.Errorf("expected %s to not equal %s", early, late)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                          "0m",
+		50 * time.Minute:           "50m",
+		2 * time.Hour:              "2h",
+		time.Hour + 15*time.Minute: "1h 15m",
+		3*time.Hour + 5*time.Minute + 30*time.Second: "3h 5m",
+	}
+
+	for d, want := range cases {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
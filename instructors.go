@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// IndexedInstructor is an instructor seen while scraping, stored in Redis to avoid live Banner lookups
+type IndexedInstructor struct {
+	BannerId string `json:"bannerId"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+}
+
+func (instructor IndexedInstructor) MarshalBinary() ([]byte, error) {
+	return json.Marshal(instructor)
+}
+
+// NormalizeInstructorName canonicalizes an instructor's name for indexing & matching.
+// Banner's "Last, First" order is flipped to "first last", and the result is lowercased with periods and extra whitespace removed (e.g. "Smith, J. Robert" becomes "j robert smith").
+func NormalizeInstructorName(name string) string {
+	if last, first, found := strings.Cut(name, ","); found {
+		name = first + " " + last
+	}
+
+	name = strings.ReplaceAll(strings.ToLower(name), ".", " ")
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// InstructorNameMatches returns true if the search matches the instructor's name, with both normalized.
+// Besides partial matches, a first name may be shortened to an initial or prefix (e.g. "j smith" matches "john smith").
+func InstructorNameMatches(name string, search string) bool {
+	name, search = NormalizeInstructorName(name), NormalizeInstructorName(search)
+	if strings.Contains(name, search) {
+		return true
+	}
+
+	nameParts, searchParts := strings.Fields(name), strings.Fields(search)
+	if len(nameParts) < 2 || len(searchParts) < 2 {
+		return false
+	}
+
+	return nameParts[len(nameParts)-1] == searchParts[len(searchParts)-1] && strings.HasPrefix(nameParts[0], searchParts[0])
+}
+
+// IndexInstructors records the instructors of a course.
+// Each instructor is stored under instructor:<term>:<id>, with a normalized name to ID lookup in instructors:<term>.
+func IndexInstructors(ctx context.Context, course Course) error {
+	if len(course.Faculty) == 0 {
+		return nil
+	}
+
+	names := map[string]interface{}{}
+	pipe := kv.Pipeline()
+	for _, faculty := range course.Faculty {
+		if faculty.BannerId == "" {
+			continue
+		}
+
+		pipe.Set(ctx, InstructorKey(course.Term, faculty.BannerId), IndexedInstructor{
+			BannerId: faculty.BannerId,
+			Name:     faculty.DisplayName,
+			Email:    faculty.Email,
+		}, 0)
+		names[NormalizeInstructorName(faculty.DisplayName)] = faculty.BannerId
+	}
+
+	if len(names) > 0 {
+		pipe.HSet(ctx, InstructorsKey(course.Term), names)
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetIndexedInstructor retrieves an instructor from the scrape-time index by their Banner ID
+func GetIndexedInstructor(ctx context.Context, term string, bannerId string) (*IndexedInstructor, error) {
+	raw, err := kv.Get(ctx, InstructorKey(term, bannerId)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, fmt.Errorf("instructor not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get instructor: %w", err)
+	}
+
+	var instructor IndexedInstructor
+	err = json.Unmarshal([]byte(raw), &instructor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal instructor: %w", err)
+	}
+
+	return &instructor, nil
+}
+
+// FindInstructors returns the Banner IDs of indexed instructors whose names match the search, keyed by their normalized name.
+// At most max results are returned, sorted by name; this is fast enough for use in autocomplete.
+func FindInstructors(ctx context.Context, term string, search string, max int) (map[string]string, error) {
+	names, err := kv.HGetAll(ctx, InstructorsKey(term)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instructor names: %w", err)
+	}
+
+	matches := make([]string, 0, max)
+	for name := range names {
+		if InstructorNameMatches(name, search) {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+
+	results := make(map[string]string, min(max, len(matches)))
+	for _, name := range matches[:min(max, len(matches))] {
+		results[name] = names[name]
+	}
+
+	return results, nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+)
+
+// RedisKey joins the given parts into a Redis key under the configured prefix (e.g. "production:class:12345").
+// Prefer the specific key constructors below, so each key format is defined in exactly one place.
+func RedisKey(parts ...string) string {
+	key := strings.Join(parts, ":")
+	if redisKeyPrefix == "" {
+		return key
+	}
+	return redisKeyPrefix + ":" + key
+}
+
+// ClassKey is the key of a scraped course, stored as JSON
+func ClassKey(crn string) string {
+	return RedisKey("class", crn)
+}
+
+// ScrapedKey is the key marking a subject as recently scraped, holding the number of courses found
+func ScrapedKey(subject string, term string) string {
+	return RedisKey("scraped", subject, term)
+}
+
+// SubjectKey is the key of the set of scraped CRNs for a subject within the term
+func SubjectKey(term string, subject string) string {
+	return RedisKey("subject", term, subject)
+}
+
+// EmptyScrapesKey is the key counting consecutive scrapes of a subject that found no classes
+func EmptyScrapesKey(subject string, term string) string {
+	return RedisKey("emptyscrapes", subject, term)
+}
+
+// ScrapeLockKey is the key of the lock held by the instance currently scraping
+func ScrapeLockKey() string {
+	return RedisKey("scrape", "lock")
+}
+
+// TermsKey is the key of the terms last fetched from Banner, stored as JSON
+func TermsKey() string {
+	return RedisKey("terms")
+}
+
+// SearchCacheKey builds the Redis key used to cache the results of a search.
+// The query is identified by its stable key, alongside the term and sort parameters.
+func SearchCacheKey(term string, query *Query, sort string, sortDescending bool) string {
+	return RedisKey("search", term, query.Key(), strings.ToLower(sort), strconv.FormatBool(sortDescending))
+}
+
+// ChangesKey is the key of the list of changes recorded for courses in the term
+func ChangesKey(term string) string {
+	return RedisKey("changes", term)
+}
+
+// InstructorKey is the key of an indexed instructor within the term
+func InstructorKey(term string, bannerId string) string {
+	return RedisKey("instructor", term, bannerId)
+}
+
+// InstructorsKey is the key of the hash of instructor names within the term
+func InstructorsKey(term string) string {
+	return RedisKey("instructors", term)
+}
+
+// ScheduleTypesKey is the key of the set of meeting schedule type codes seen while scraping
+func ScheduleTypesKey() string {
+	return RedisKey("scheduletypes")
+}
+
+// BuildingsKey is the key of the hash of building codes & names
+func BuildingsKey() string {
+	return RedisKey("buildings")
+}
+
+// RatingKey is the key of a cached RateMyProfessors rating, by the instructor's normalized name
+func RatingKey(name string) string {
+	return RedisKey("rmp", strings.ToLower(name))
+}
+
+// DigestKey is the key of the list of sections opened since the last digest
+func DigestKey() string {
+	return RedisKey("digest", "opened")
+}
+
+// DigestGuildsKey is the key of the set of guilds receiving digests
+func DigestGuildsKey() string {
+	return RedisKey("digest", "guilds")
+}
+
+// GuildNameKey is the key of a guild's cached name
+func GuildNameKey(guildID string) string {
+	return RedisKey("guild", guildID, "name")
+}
+
+// ChannelNameKey is the key of a channel's cached name
+func ChannelNameKey(channelID string) string {
+	return RedisKey("channel", channelID, "name")
+}
+
+// GuildConfigKey is the key of a guild's configuration
+func GuildConfigKey(guildID string) string {
+	return RedisKey("guildconfig", guildID)
+}
+
+// CommandsKey is the key of the hash of registered command hashes, for a guild or globally (empty guild ID)
+func CommandsKey(guildTarget string) string {
+	return RedisKey("commands", lo.Ternary(guildTarget == "", "global", guildTarget))
+}
+
+// ActiveTermKey is the key of the term pinned by a user within a channel
+func ActiveTermKey(channelID string, userID string) string {
+	return RedisKey("activeterm", channelID, userID)
+}
+
+// RecentKey is the key of a user's list of recent searches
+func RecentKey(userID string) string {
+	return RedisKey("recent", userID)
+}
+
+// RecentOptInKey is the key marking a user as opted in to recording recent searches
+func RecentOptInKey(userID string) string {
+	return RedisKey("recent", "optin", userID)
+}
+
+// FeedbackCooldownKey is the key preventing a user from sending feedback too often
+func FeedbackCooldownKey(userID string) string {
+	return RedisKey("feedback", "cooldown", userID)
+}
+
+// legacyKeyNamespaces are the namespaces of the long-lived keys written before keys were prefixed.
+// Short-lived keys (e.g. search caches, cooldowns, the scrape lock) are left to expire instead.
+var legacyKeyNamespaces = []string{"class", "scraped", "guild", "channel", "guildconfig", "changes", "commands", "instructor", "instructors", "recent", "rmp"}
+
+// FindUnprefixedKeys returns the keys written before keys were prefixed, which the configured prefix no longer reaches.
+// Nothing is returned when keys are unprefixed (REDIS_KEY_PREFIX is empty), as the old keys are still in use.
+func FindUnprefixedKeys(ctx context.Context) ([]string, error) {
+	if redisKeyPrefix == "" {
+		return nil, nil
+	}
+
+	keys := []string{}
+	for _, namespace := range legacyKeyNamespaces {
+		iter := kv.Scan(ctx, 0, namespace+":*", 1000).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return nil, fmt.Errorf("failed to scan for unprefixed keys: %w", err)
+		}
+	}
+
+	return keys, nil
+}
+
+// CheckUnprefixedKeys warns about keys written before keys were prefixed, or moves them under the prefix when migrate is set (REDIS_MIGRATE_KEYS).
+// Keys are renamed without overwriting, so a key already written under the prefix is kept and the unprefixed one is left behind.
+func CheckUnprefixedKeys(ctx context.Context, migrate bool) error {
+	keys, err := FindUnprefixedKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if !migrate {
+		log.Warn().Int("count", len(keys)).Str("prefix", redisKeyPrefix).
+			Msg("Unprefixed Redis keys found, which are no longer read; set REDIS_MIGRATE_KEYS=true to move them under the prefix, or REDIS_KEY_PREFIX= to keep using them")
+		return nil
+	}
+
+	moved, skipped := 0, 0
+	for _, key := range keys {
+		renamed, err := kv.RenameNX(ctx, key, RedisKey(key)).Result()
+		if err != nil {
+			return fmt.Errorf("failed to migrate key %s: %w", key, err)
+		}
+
+		if renamed {
+			moved++
+		} else {
+			skipped++
+		}
+	}
+
+	log.Info().Int("moved", moved).Int("skipped", skipped).Str("prefix", redisKeyPrefix).Msg("Migrated unprefixed Redis keys")
+	return nil
+}
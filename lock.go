@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// instanceID uniquely identifies this instance as the holder of a lock
+	instanceID = RandomString(12)
+
+	// Only extend or release the lock if it is still held by this instance
+	renewLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// Lock is a Redis-backed lock held by this instance, renewed in the background until released
+type Lock struct {
+	key  string
+	ttl  time.Duration
+	stop chan struct{}
+	// released ensures the lock is only released once, as a later release could delete a newer lock with the same key
+	released sync.Once
+}
+
+// AcquireLock attempts to acquire the lock with the given key.
+// If the lock is held by another instance, nil is returned without an error.
+// The lock expires after the TTL unless renewed, so a crashed instance cannot hold it forever.
+func AcquireLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	acquired, err := kv.SetNX(ctx, key, instanceID, ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+
+	if !acquired {
+		return nil, nil
+	}
+
+	lock := &Lock{key: key, ttl: ttl, stop: make(chan struct{})}
+	go lock.renew(ctx)
+
+	return lock, nil
+}
+
+// renew extends the lock's TTL periodically until it is released
+func (l *Lock) renew(ctx context.Context) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			renewed, err := renewLockScript.Run(ctx, kv, []string{l.key}, instanceID, l.ttl.Milliseconds()).Int()
+			if err != nil {
+				log.Error().Stack().Err(err).Str("key", l.key).Msg("Failed to renew lock")
+			} else if renewed == 0 {
+				log.Warn().Str("key", l.key).Msg("Lock was lost before it could be renewed")
+				return
+			}
+		}
+	}
+}
+
+// Release stops renewing the lock and releases it, if still held by this instance.
+// Releasing an already released lock does nothing.
+func (l *Lock) Release(ctx context.Context) error {
+	var err error
+	l.released.Do(func() {
+		close(l.stop)
+
+		err = releaseLockScript.Run(ctx, kv, []string{l.key}, instanceID).Err()
+		if err != nil {
+			err = fmt.Errorf("failed to release lock: %w", err)
+		}
+	})
+	return err
+}
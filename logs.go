@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"io"
 	"os"
 
@@ -33,3 +34,16 @@ func (l logSplitter) WriteLevel(level zerolog.Level, p []byte) (n int, err error
 		return l.err.Write(p)
 	}
 }
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of the context carrying the given request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID carried by the context, or an empty string if there is none
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
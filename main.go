@@ -2,14 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"net/http"
 	"net/http/cookiejar"
 	_ "net/http/pprof"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 	_ "time/tzdata"
@@ -21,11 +25,13 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/rs/zerolog/pkgerrors"
 	"github.com/samber/lo"
+	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
 
 var (
 	ctx                 context.Context
+	cancelCtx           context.CancelFunc // Cancels ctx, aborting in-flight requests on shutdown
 	kv                  *redis.Client
 	session             *discordgo.Session
 	client              http.Client
@@ -33,9 +39,36 @@ var (
 	isDevelopment       bool
 	baseURL             string // Base URL for all requests to the banner system
 	environment         string
-	p                   *message.Printer = message.NewPrinter(message.MatchLanguage("en"))
+	p                   *message.Printer = message.NewPrinter(language.English)
 	CentralTimeLocation *time.Location
-	isClosing           bool = false
+	isClosing           atomic.Bool                                   // Set on shutdown, read concurrently by every interaction
+	isReady             atomic.Bool                                   // Set once startup state (e.g. terms) is loaded, commands are rejected until then
+	searchCacheTTL      time.Duration   = 90 * time.Second            // How long identical searches are served from Redis, zero to disable
+	requestTimeout      time.Duration   = 10 * time.Second            // The maximum time a single Banner request may take, including reading the body
+	requestLogSampler   zerolog.Sampler = &zerolog.BasicSampler{N: 1} // Samples the request/response logs in DoRequest
+	adminUserIDs        []string                                      // Discord user IDs allowed to use privileged commands
+	adminRoleIDs        []string                                      // Discord guild role IDs allowed to use privileged commands
+	bannerPathPrefix    string                                        // The path of baseURL (e.g. /StudentRegistrationSsb/ssb), which prefixes every request path
+	ratingsEnabled      bool                                          // Whether RateMyProfessors ratings are looked up for instructors (RMP_ENABLE)
+	forcedTerm          *Term                                         // Overrides the default term everywhere when set (FORCE_TERM), for testing outside of a term
+	feedbackChannelID   string                                        // The channel /feedback reports are posted to, disabled when empty
+	dumpsDir            string          = "dumps"                     // The directory response dumps are written to
+	dumpsMaxCount       int             = 50                          // The maximum number of dumps kept, zero for no limit
+	dumpsMaxAge         time.Duration   = 7 * 24 * time.Hour          // The maximum age of kept dumps, zero for no limit
+	maxEmbedFields      int             = 25                          // The maximum number of fields used in a single embed (Discord allows up to 25)
+	redisKeyPrefix      string                                        // Prefixes every Redis key (REDIS_KEY_PREFIX), isolating environments that share a Redis instance
+	migrateRedisKeys    bool                                          // Moves keys written before keys were prefixed under the prefix on startup (REDIS_MIGRATE_KEYS)
+	scrapeAllowSubjects []string                                      // When set, only these subjects are scraped (SCRAPE_SUBJECTS_ALLOW)
+	scrapeDenySubjects  []string                                      // Subjects never scraped (SCRAPE_SUBJECTS_DENY)
+	dryRun              bool                                          // Banner requests are logged & answered with fixtures instead of being sent (DRY_RUN)
+	dryRunFixturesDir   string          = "docs/samples"              // The directory dry-run fixtures are read from (DRY_RUN_FIXTURES)
+	commandPrefix       string                                        // Prefixes every registered command name (COMMAND_PREFIX), so several bots can share a guild
+	digestInterval      time.Duration   = time.Hour                   // How often newly opened sections are posted to subscribed channels, zero to disable (DIGEST_INTERVAL)
+	bannerUsername      string                                        // Logs in to Banner during session setup when set (BANNER_USERNAME), anonymous otherwise
+	bannerPassword      string                                        // The password for bannerUsername (BANNER_PASSWORD)
+	bannerLoginPath     string          = "/login/auth"               // The path credentials are posted to (BANNER_LOGIN_PATH)
+	// Where commands are registered, 'guild' (BOT_TARGET_GUILD) or 'global'
+	registerScope = flag.String("register", "", "Where to register commands: 'guild' or 'global', defaults to guild in development")
 )
 
 const (
@@ -50,7 +83,7 @@ func init() {
 		log.Debug().Err(err).Msg("Error loading .env file")
 	}
 
-	ctx = context.Background()
+	ctx, cancelCtx = context.WithCancel(context.Background())
 
 	var err error
 	CentralTimeLocation, err = time.LoadLocation(CentralTimezoneName)
@@ -65,12 +98,22 @@ func init() {
 
 	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
 
+	// Contexts without a scoped logger fall back to the global logger
+	zerolog.DefaultContextLogger = &log.Logger
+
 	// Try to grab the environment variable, or default to development
 	environment = GetFirstEnv("ENVIRONMENT", "RAILWAY_ENVIRONMENT")
 	if environment == "" {
 		environment = "development"
 	}
 
+	// Namespace Redis keys by environment, unless explicitly configured (an empty prefix leaves keys unprefixed)
+	redisKeyPrefix = environment
+	if prefix, ok := os.LookupEnv("REDIS_KEY_PREFIX"); ok {
+		redisKeyPrefix = prefix
+	}
+	migrateRedisKeys = strings.EqualFold(os.Getenv("REDIS_MIGRATE_KEYS"), "true")
+
 	// Use the custom console writer if we're in development
 	isDevelopment = environment == "development"
 	if isDevelopment {
@@ -78,19 +121,145 @@ func init() {
 	} else {
 		log.Logger = zerolog.New(logSplitter{std: os.Stdout, err: os.Stderr}).With().Timestamp().Logger()
 	}
-	log.Debug().Str("environment", environment).Msg("Loggers Setup")
+
+	// Allow the log level to be configured (e.g. "debug", "info", "warn")
+	if rawLevel := os.Getenv("LOG_LEVEL"); rawLevel != "" {
+		level, err := zerolog.ParseLevel(strings.ToLower(rawLevel))
+		if err != nil {
+			log.Warn().Err(err).Str("value", rawLevel).Msg("Invalid LOG_LEVEL, logging all levels")
+		} else {
+			zerolog.SetGlobalLevel(level)
+		}
+	}
+
+	// Allow the request/response logs to be sampled (e.g. "10" logs one in every ten requests)
+	if rawRate := os.Getenv("LOG_REQUEST_SAMPLE_RATE"); rawRate != "" {
+		rate, err := strconv.ParseUint(rawRate, 10, 32)
+		if err != nil || rate == 0 {
+			log.Warn().Err(err).Str("value", rawRate).Msg("Invalid LOG_REQUEST_SAMPLE_RATE, logging every request")
+		} else {
+			requestLogSampler = &zerolog.BasicSampler{N: uint32(rate)}
+		}
+	}
+
+	log.Debug().Str("environment", environment).Str("redisKeyPrefix", redisKeyPrefix).Str("logLevel", zerolog.GlobalLevel().String()).Msg("Loggers Setup")
 
 	// Set discordgo's logger to use zerolog
 	discordgo.Logger = DiscordGoLogger
 
-	baseURL = os.Getenv("BANNER_BASE_URL")
+	// Validated in main, before any request is made
+	baseURL = strings.TrimSuffix(os.Getenv("BANNER_BASE_URL"), "/")
+	// The base URL may still be invalid here, leaving the prefix empty until main rejects it
+	if parsedBaseURL, err := url.Parse(baseURL); err == nil {
+		bannerPathPrefix = parsedBaseURL.Path
+	}
+
+	// Allow the default term to be forced (e.g. "202520"), for developing against a known-good term
+	if rawTerm := os.Getenv("FORCE_TERM"); rawTerm != "" {
+		if !IsValidTermCode(rawTerm) {
+			log.Fatal().Str("value", rawTerm).Msg("Invalid FORCE_TERM, must be a Banner term code (e.g. 202520)")
+		}
+
+		term := ParseTerm(rawTerm)
+		forcedTerm = &term
+		log.Info().Str("term", rawTerm).Msg("Default term forced")
+	}
+
+	// Allow the search cache TTL to be overridden (e.g. "2m", "0s" to disable)
+	searchCacheTTL = GetDurationEnv("SEARCH_CACHE_TTL", searchCacheTTL)
+
+	// Allow the Banner request timeout to be overridden (e.g. "5s")
+	requestTimeout = GetDurationEnv("BANNER_TIMEOUT", requestTimeout)
+
+	// Allow Banner to be left untouched while developing, answering requests with fixtures
+	dryRun = strings.EqualFold(os.Getenv("DRY_RUN"), "true")
+	if dir := os.Getenv("DRY_RUN_FIXTURES"); dir != "" {
+		dryRunFixturesDir = dir
+	}
+	if dryRun {
+		log.Warn().Str("fixtures", dryRunFixturesDir).Msg("Dry run enabled, Banner requests will not be sent")
+	}
+
+	// Some Banner instances gate class search behind a login; sessions are anonymous unless credentials are given
+	bannerUsername = GetFirstEnv("BANNER_USERNAME")
+	bannerPassword = GetFirstEnv("BANNER_PASSWORD")
+	if path := os.Getenv("BANNER_LOGIN_PATH"); path != "" {
+		bannerLoginPath = path
+	}
+
+	// The channel user feedback is sent to
+	feedbackChannelID = os.Getenv("FEEDBACK_CHANNEL_ID")
+
+	// RateMyProfessors is an external dependency, so ratings are opt-in
+	ratingsEnabled = strings.EqualFold(os.Getenv("RMP_ENABLE"), "true")
+
+	// Configure where response dumps are kept, and how many
+	if dir := os.Getenv("DUMPS_DIR"); dir != "" {
+		dumpsDir = dir
+	}
+	if rawCount := os.Getenv("DUMPS_MAX_COUNT"); rawCount != "" {
+		count, err := strconv.Atoi(rawCount)
+		if err != nil || count < 0 {
+			log.Warn().Err(err).Str("value", rawCount).Int("fallback", dumpsMaxCount).Msg("Invalid DUMPS_MAX_COUNT")
+		} else {
+			dumpsMaxCount = count
+		}
+	}
+	dumpsMaxAge = GetDurationEnv("DUMPS_MAX_AGE", dumpsMaxAge)
+
+	// Allow the opened section digest to be posted more or less often (e.g. "24h", "0s" to disable)
+	digestInterval = GetDurationEnv("DIGEST_INTERVAL", digestInterval)
+
+	// Allow fewer embed fields to be used, for more compact responses
+	if rawFields := os.Getenv("MAX_EMBED_FIELDS"); rawFields != "" {
+		fields, err := strconv.Atoi(rawFields)
+		if err != nil || fields < FieldsPerSearchResult || fields > 25 {
+			log.Warn().Err(err).Str("value", rawFields).Int("fallback", maxEmbedFields).Msgf("Invalid MAX_EMBED_FIELDS, must be %d-25", FieldsPerSearchResult)
+		} else {
+			maxEmbedFields = fields
+		}
+	}
+
+	// Namespace the command names, e.g. "dev-" for a development bot sharing a guild with production
+	commandPrefix = strings.ToLower(strings.TrimSpace(os.Getenv("COMMAND_PREFIX")))
+
+	// Parse the admin user & role IDs (comma separated)
+	adminUserIDs = GetListEnv("ADMIN_USER_IDS")
+	adminRoleIDs = GetListEnv("ADMIN_ROLE_IDS")
+
+	// Bound the subjects scraped (comma separated), scraping every subject by default
+	scrapeAllowSubjects = ParseSubjects(os.Getenv("SCRAPE_SUBJECTS_ALLOW"))
+	scrapeDenySubjects = ParseSubjects(os.Getenv("SCRAPE_SUBJECTS_DENY"))
+
+	// Load the optional building map links
+	if path := os.Getenv("BUILDING_MAPS_FILE"); path != "" {
+		if err := LoadBuildingMaps(path); err != nil {
+			log.Warn().Err(err).Str("path", path).Msg("Cannot load building maps, map links disabled")
+		}
+	}
+}
+
+// UnavailableReason returns why an interaction of the given type cannot be handled right now, or an empty string if it can.
+// This is checked concurrently by every interaction, while startup & shutdown toggle isReady & isClosing.
+func UnavailableReason(interactionType discordgo.InteractionType) string {
+	// Handle commands during restart (highly unlikely, but just in case)
+	if isClosing.Load() {
+		return "Bot is currently restarting, try again later."
+	}
+
+	// Handle commands during startup, before terms are loaded (autocomplete doesn't need them)
+	if !isReady.Load() && interactionType != discordgo.InteractionApplicationCommandAutocomplete {
+		return "Bot is starting up, try again shortly."
+	}
+
+	return ""
 }
 
 func initRedis() {
 	// Setup redis
 	redisUrl := GetFirstEnv("REDIS_URL", "REDIS_PRIVATE_URL")
 	if redisUrl == "" {
-		log.Fatal().Stack().Msg("REDIS_URL/REDIS_PRIVATE_URL not set")
+		log.Fatal().Stack().Msg("REDIS_URL/REDIS_PRIVATE_URL (or a _FILE variant) not set")
 	}
 
 	// Parse URL and create client
@@ -133,11 +302,52 @@ func initRedis() {
 	}
 }
 
+// ValidateConfig checks that all required configuration & clients are set up, returning every problem found.
+// This catches setup mistakes at startup, rather than as a nil dereference in the middle of a command.
+func ValidateConfig() error {
+	var errs []error
+
+	if err := ValidateBaseURL(baseURL); err != nil {
+		errs = append(errs, fmt.Errorf("base URL: %w", err))
+	}
+	if kv == nil {
+		errs = append(errs, errors.New("redis client is not set up"))
+	}
+	if client.Jar == nil {
+		errs = append(errs, errors.New("http client has no cookie jar"))
+	}
+	if client.Timeout <= 0 {
+		errs = append(errs, errors.New("http client has no timeout"))
+	}
+	if CentralTimeLocation == nil {
+		errs = append(errs, errors.New("timezone is not loaded"))
+	}
+	if environment == "" {
+		errs = append(errs, errors.New("environment is not set"))
+	}
+	if err := ValidateCommandPrefix(commandPrefix, commandDefinitions); err != nil {
+		errs = append(errs, err)
+	}
+	if (bannerUsername == "") != (bannerPassword == "") {
+		errs = append(errs, errors.New("BANNER_USERNAME and BANNER_PASSWORD must be set together"))
+	}
+	if GetFirstEnv("BOT_TOKEN") == "" {
+		errs = append(errs, errors.New("BOT_TOKEN/BOT_TOKEN_FILE is not set"))
+	}
+
+	return errors.Join(errs...)
+}
+
 func main() {
 	flag.Parse()
 
 	initRedis()
 
+	// Keys written before keys were prefixed are no longer read, so they are flagged (or migrated) before anything is cached again
+	if err := CheckUnprefixedKeys(ctx, migrateRedisKeys); err != nil {
+		log.Error().Err(err).Msg("Cannot check for unprefixed Redis keys")
+	}
+
 	if strings.EqualFold(os.Getenv("PPROF_ENABLE"), "true") {
 		// Start pprof server
 		go func() {
@@ -159,11 +369,19 @@ func main() {
 	}
 
 	// Create client, setup session (acquire cookies)
-	client = http.Client{Jar: cookies}
-	setup()
+	client = http.Client{Jar: cookies, Timeout: requestTimeout}
+
+	if err := ValidateConfig(); err != nil {
+		log.Fatal().Err(err).Msg("Invalid configuration")
+	}
+
+	err = setup()
+	if err != nil {
+		log.Err(err).Msg("Session setup failed, Banner requests may fail")
+	}
 
 	// Create discord session
-	session, err = discordgo.New("Bot " + os.Getenv("BOT_TOKEN"))
+	session, err = discordgo.New("Bot " + GetFirstEnv("BOT_TOKEN"))
 	if err != nil {
 		log.Err(err).Msg("Invalid bot parameters")
 	}
@@ -179,24 +397,63 @@ func main() {
 
 	// Setup command handlers
 	session.AddHandler(func(internalSession *discordgo.Session, interaction *discordgo.InteractionCreate) {
-		// Handle commands during restart (highly unlikely, but just in case)
-		if isClosing {
-			err := RespondError(internalSession, interaction.Interaction, "Bot is currently restarting, try again later.", nil)
+		// Reject commands during startup & restart
+		if reason := UnavailableReason(interaction.Type); reason != "" {
+			// Autocomplete can only be answered with choices, so it gets none instead of an error message
+			if interaction.Type == discordgo.InteractionApplicationCommandAutocomplete {
+				err := internalSession.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
+					Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+					Data: &discordgo.InteractionResponseData{Choices: []*discordgo.ApplicationCommandOptionChoice{}},
+				})
+				if err != nil {
+					log.Error().Err(err).Msg("Failed to respond with empty autocomplete choices")
+				}
+				return
+			}
+
+			err := RespondErrorWithLevel(ctx, internalSession, interaction.Interaction, ErrorLevelUser, reason, nil)
 			if err != nil {
-				log.Error().Err(err).Msg("Failed to respond with restart error feedback")
+				log.Error().Err(err).Msg("Failed to respond with unavailable error feedback")
+			}
+			return
+		}
+
+		// Scope a logger to this invocation, identified by a short request ID shared with any error responses
+		requestID := RandomString(8)
+		logger := log.With().Str("requestID", requestID).Logger()
+		commandCtx := WithResponseState(WithRequestID(logger.WithContext(ctx), requestID))
+
+		name := UnprefixedCommandName(interaction.ApplicationCommandData().Name)
+
+		// Autocomplete interactions are answered with choices, never with a regular response
+		if interaction.Type == discordgo.InteractionApplicationCommandAutocomplete {
+			if handler, ok := autocompleteHandlers[name]; ok {
+				err := handler(commandCtx, internalSession, interaction)
+				if err != nil {
+					logger.Warn().Err(err).Str("commandName", name).Msg("Autocomplete Handler Failed")
+				}
 			}
 			return
 		}
 
-		name := interaction.ApplicationCommandData().Name
 		if handler, ok := commandHandlers[name]; ok {
+			// Reject unauthorized use of privileged commands before the handler runs
+			if privilegedCommands[name] && !IsAdmin(interaction) {
+				logger.Warn().Str("commandName", name).Str("user", GetUser(interaction).ID).Msg("Unauthorized Privileged Command")
+				err := RespondErrorWithLevel(commandCtx, internalSession, interaction.Interaction, ErrorLevelUser, "You are not allowed to use this command.", nil)
+				if err != nil {
+					logger.Error().Stack().Str("commandName", name).Err(err).Msg("Failed to respond with authorization error feedback")
+				}
+				return
+			}
+
 			// Build dict of options for the log
 			options := zerolog.Dict()
 			for _, option := range interaction.ApplicationCommandData().Options {
 				options.Str(option.Name, fmt.Sprintf("%v", option.Value))
 			}
 
-			event := log.Info().Str("name", name).Str("user", GetUser(interaction).Username).Dict("options", options)
+			event := logger.Info().Str("name", name).Str("user", GetUser(interaction).Username).Dict("options", options)
 
 			// If the command was invoked in a guild, add guild & channel info to the log
 			if interaction.Member != nil {
@@ -207,7 +464,7 @@ func main() {
 
 				channel := zerolog.Dict()
 				channel.Str("id", interaction.ChannelID)
-				guild.Str("name", GetChannelName(interaction.ChannelID))
+				channel.Str("name", GetChannelName(interaction.ChannelID))
 				event.Dict("channel", channel)
 			} else {
 				// If the command was invoked in a DM, add the user info to the log
@@ -223,100 +480,194 @@ func main() {
 			// Prepare to recover
 			defer func() {
 				if err := recover(); err != nil {
-					log.Error().Stack().Str("commandName", name).Interface("detail", err).Msg("Command Handler Panic")
+					logger.Error().Stack().Str("commandName", name).Interface("detail", err).Msg("Command Handler Panic")
 
 					// Respond with error
-					err := RespondError(internalSession, interaction.Interaction, "Unexpected Error: command handler panic", nil)
+					message := fmt.Sprintf("%s\nIf this keeps happening, report it with the error ID below.", PanicMessage(err))
+					err := RespondError(commandCtx, internalSession, interaction.Interaction, message, nil)
 					if err != nil {
-						log.Error().Stack().Str("commandName", name).Err(err).Msg("Failed to respond with panic error feedback")
+						logger.Error().Stack().Str("commandName", name).Err(err).Msg("Failed to respond with panic error feedback")
 					}
 				}
 			}()
 
 			// Call handler
-			err := handler(internalSession, interaction)
+			err := handler(commandCtx, internalSession, interaction)
 
 			// Log & respond error
 			if err != nil {
+				// User errors are expected, and are shown to the user as-is
+				var userErr *UserError
+				if errors.As(err, &userErr) {
+					logger.Debug().Str("commandName", name).Err(err).Msg("Command User Error")
+					err = RespondErrorWithLevel(commandCtx, internalSession, interaction.Interaction, ErrorLevelUser, userErr.Message, nil)
+					if err != nil {
+						logger.Error().Stack().Str("commandName", name).Err(err).Msg("Failed to respond with error feedback")
+					}
+					return
+				}
+
 				// TODO: Find a way to merge the response with the handler's error
-				log.Error().Str("commandName", name).Err(err).Msg("Command Handler Error")
+				logger.Error().Str("commandName", name).Err(err).Msg("Command Handler Error")
 
 				// Respond with error
-				err = RespondError(internalSession, interaction.Interaction, fmt.Sprintf("Unexpected Error: %s", err.Error()), nil)
+				err = RespondError(commandCtx, internalSession, interaction.Interaction, fmt.Sprintf("Unexpected Error: %s", err.Error()), nil)
 				if err != nil {
-					log.Error().Stack().Str("commandName", name).Err(err).Msg("Failed to respond with error feedback")
+					logger.Error().Stack().Str("commandName", name).Err(err).Msg("Failed to respond with error feedback")
 				}
 			}
 
 		} else {
-			log.Error().Stack().Str("commandName", name).Msg("Command Interaction Has No Handler")
+			logger.Error().Stack().Str("commandName", name).Msg("Command Interaction Has No Handler")
 
 			// Respond with error
-			RespondError(internalSession, interaction.Interaction, "Unexpected Error: interaction has no handler", nil)
+			RespondError(commandCtx, internalSession, interaction.Interaction, "Unexpected Error: interaction has no handler", nil)
 		}
 	})
 
 	// Register commands with discord
+	definitions := PrefixCommands(commandDefinitions, commandPrefix)
 	arr := zerolog.Arr()
-	lo.ForEach(commandDefinitions, func(cmd *discordgo.ApplicationCommand, _ int) {
+	lo.ForEach(definitions, func(cmd *discordgo.ApplicationCommand, _ int) {
 		arr.Str(cmd.Name)
 	})
 	log.Info().Array("commands", arr).Msg("Registering commands")
 
-	// In development, use test server, otherwise empty (global) for command registration
+	// Guild registration is instant, while global registration can take up to an hour to propagate.
+	// Development defaults to the test guild, otherwise commands are registered globally (empty target).
+	scope := *registerScope
+	if scope == "" {
+		scope = lo.Ternary(isDevelopment, "guild", "global")
+	}
+
 	guildTarget := ""
-	if isDevelopment {
+	switch scope {
+	case "guild":
 		guildTarget = os.Getenv("BOT_TARGET_GUILD")
+		if guildTarget == "" {
+			if *registerScope == "guild" {
+				log.Fatal().Msg("BOT_TARGET_GUILD must be set to register commands to a guild")
+			}
+			log.Warn().Msg("BOT_TARGET_GUILD not set in development, registering commands globally (may take up to an hour to appear)")
+		}
+	case "global":
+	default:
+		log.Fatal().Str("register", scope).Msg("Invalid -register scope, must be 'guild' or 'global'")
 	}
+	log.Info().Str("scope", lo.Ternary(guildTarget == "", "global", "guild")).Str("guild", guildTarget).Msg("Command registration target")
 
-	// Register commands
-	existingCommands, err := session.ApplicationCommands(session.State.User.ID, guildTarget)
+	// Register commands, skipping those unchanged since the last startup
+	err = RegisterCommands(ctx, session, guildTarget, definitions)
 	if err != nil {
-		log.Fatal().Stack().Err(err).Msg("Cannot get existing commands")
+		log.Fatal().Stack().Err(err).Msg("Cannot register commands")
 	}
-	newCommands, err := session.ApplicationCommandBulkOverwrite(session.State.User.ID, guildTarget, commandDefinitions)
+
+	// Remove commands that are no longer defined. When registering globally, commands left in the test guild
+	// would show up twice, so they are all removed; global commands are only removed if no longer defined.
+	err = UnregisterStaleCommands(ctx, session, guildTarget, definitions)
 	if err != nil {
-		log.Fatal().Stack().Err(err).Msg("Cannot register commands")
+		log.Err(err).Stack().Msg("Cannot unregister stale commands")
 	}
 
-	// Compare existing commands with new commands
-	for _, newCommand := range newCommands {
-		existingCommand, found := lo.Find(existingCommands, func(cmd *discordgo.ApplicationCommand) bool {
-			return cmd.Name == newCommand.Name
-		})
+	otherErr := error(nil)
+	if testGuild := os.Getenv("BOT_TARGET_GUILD"); guildTarget == "" && testGuild != "" {
+		otherErr = UnregisterStaleCommands(ctx, session, testGuild, nil)
+	} else if guildTarget != "" {
+		otherErr = UnregisterStaleCommands(ctx, session, "", definitions)
+	}
+	if otherErr != nil {
+		log.Err(otherErr).Stack().Msg("Cannot unregister stale commands from the other scope")
+	}
 
-		// New command
-		if !found {
-			log.Info().Str("commandName", newCommand.Name).Msg("Registered new command")
-			continue
-		}
+	// Fetch terms on startup, falling back to the cached terms (or none, using the computed default term) if Banner is unavailable
+	err = TryReloadTerms(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Cannot fetch terms on startup, retrying in the background")
 
-		// Compare versions
-		if newCommand.Version != existingCommand.Version {
-			log.Info().Str("commandName", newCommand.Name).
-				Str("oldVersion", existingCommand.Version).Str("newVersion", newCommand.Version).
-				Msg("Command Updated")
+		err = LoadCachedTerms(ctx)
+		if err != nil {
+			log.Warn().Err(err).Str("defaultTerm", Default(time.Now()).ToString()).Msg("No cached terms available")
 		}
-	}
 
-	// Fetch terms on startup
-	err = TryReloadTerms()
-	if err != nil {
-		log.Fatal().Stack().Err(err).Msg("Cannot fetch terms on startup")
+		go func() {
+			for LastTermUpdate().IsZero() {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(TermRetryInterval):
+				}
+
+				err := TryReloadTerms(ctx)
+				if err != nil {
+					log.Warn().Err(err).Msg("Cannot fetch terms, retrying later")
+				}
+			}
+			log.Info().Int("count", len(LoadedTerms())).Msg("Terms fetched after startup")
+		}()
 	}
+	isReady.Store(true)
 
 	// Launch a goroutine to scrape the banner system periodically
 	go func() {
+		// Scrape the priority majors first, so they're available soon after a deploy
+		lock, err := AcquireLock(ctx, ScrapeLockKey(), time.Minute)
+		if err != nil {
+			log.Err(err).Stack().Msg("Cannot acquire scrape lock")
+		} else if lock != nil {
+			err = WarmPriorityMajors(ctx, 2*time.Minute)
+			if err != nil {
+				log.Err(err).Stack().Msg("Priority Major Warming Failed")
+			}
+
+			err = lock.Release(ctx)
+			if err != nil {
+				log.Err(err).Stack().Msg("Cannot release scrape lock")
+			}
+		}
+
 		for {
-			err := Scrape()
+			// Only one instance may scrape at a time, the others continue serving commands
+			lock, err := AcquireLock(ctx, ScrapeLockKey(), time.Minute)
 			if err != nil {
-				log.Err(err).Stack().Msg("Periodic Scrape Failed")
+				log.Err(err).Stack().Msg("Cannot acquire scrape lock")
+			} else if lock == nil {
+				log.Debug().Msg("Scrape lock held by another instance, skipping scrape")
+			} else {
+				err = Scrape(ctx)
+				if err != nil {
+					log.Err(err).Stack().Msg("Periodic Scrape Failed")
+				}
+
+				err = lock.Release(ctx)
+				if err != nil {
+					log.Err(err).Stack().Msg("Cannot release scrape lock")
+				}
 			}
 
 			time.Sleep(3 * time.Minute)
 		}
 	}()
 
+	// Post digests of newly opened sections periodically
+	if digestInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(digestInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					err := FlushDigest(ctx, session)
+					if err != nil {
+						log.Err(err).Stack().Msg("Digest Flush Failed")
+					}
+				}
+			}
+		}()
+	}
+
 	// Close session, ensure http client closes idle connections
 	defer session.Close()
 	defer client.CloseIdleConnections()
@@ -328,7 +679,10 @@ func main() {
 
 	// Wait for signal (indefinite)
 	closingSignal := <-stop
-	isClosing = true // TODO: Switch to atomic lock with forced close after 10 seconds
+	isClosing.Store(true) // TODO: Force close after 10 seconds
+
+	// Abort any in-flight requests
+	cancelCtx()
 
 	// Defers are called after this
 	log.Warn().Str("signal", closingSignal.String()).Msg("Gracefully shutting down")
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestUnavailableReason(t *testing.T) {
+	t.Cleanup(func() {
+		isReady.Store(false)
+		isClosing.Store(false)
+	})
+
+	cases := []struct {
+		name            string
+		ready, closing  bool
+		interactionType discordgo.InteractionType
+		available       bool
+	}{
+		{"starting command", false, false, discordgo.InteractionApplicationCommand, false},
+		{"starting autocomplete", false, false, discordgo.InteractionApplicationCommandAutocomplete, true},
+		{"ready command", true, false, discordgo.InteractionApplicationCommand, true},
+		{"closing command", true, true, discordgo.InteractionApplicationCommand, false},
+		{"closing autocomplete", true, true, discordgo.InteractionApplicationCommandAutocomplete, false},
+	}
+
+	for _, c := range cases {
+		isReady.Store(c.ready)
+		isClosing.Store(c.closing)
+
+		if available := UnavailableReason(c.interactionType) == ""; available != c.available {
+			t.Errorf("%s: available = %t, want %t", c.name, available, c.available)
+		}
+	}
+}
+
+// TestUnavailableReasonConcurrent toggles the startup & shutdown flags while interactions are checked, for the race detector (go test -race)
+func TestUnavailableReasonConcurrent(t *testing.T) {
+	t.Cleanup(func() {
+		isReady.Store(false)
+		isClosing.Store(false)
+	})
+
+	const iterations = 1000
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				UnavailableReason(discordgo.InteractionApplicationCommand)
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < iterations; j++ {
+			isReady.Store(j%2 == 0)
+			isClosing.Store(j%3 == 0)
+		}
+	}()
+
+	wg.Wait()
+
+	isReady.Store(true)
+	isClosing.Store(false)
+	if reason := UnavailableReason(discordgo.InteractionApplicationCommand); reason != "" {
+		t.Errorf("expected commands to be available once ready, got %q", reason)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// Message keys used with the printer (p), which handle pluralization per language.
+// Keys are the English plural form, and are used as-is if no translation exists.
+const (
+	msgClassCount       = "%d Classes"
+	msgTermCount        = "%d terms (page %d, more available)"
+	msgTermCountOfTotal = "%d of %d terms (page %d of %d)"
+	msgBuildingCountOf  = "%d of %d Buildings"
+	msgMeetingCount     = "%d Meetings"
+)
+
+func init() {
+	set := func(key string, msg ...catalog.Message) {
+		if err := message.Set(language.English, key, msg...); err != nil {
+			panic(err)
+		}
+	}
+
+	set(msgClassCount, plural.Selectf(1, "%d",
+		"one", "%d Class",
+		"other", "%d Classes",
+	))
+	set(msgTermCount, plural.Selectf(1, "%d",
+		"one", "%d term (page %d, more available)",
+		"other", "%d terms (page %d, more available)",
+	))
+	set(msgTermCountOfTotal, plural.Selectf(2, "%d",
+		"one", "%d of %d term (page %d of %d)",
+		"other", "%d of %d terms (page %d of %d)",
+	))
+	set(msgBuildingCountOf, plural.Selectf(2, "%d",
+		"one", "%d of %d Building",
+		"other", "%d of %d Buildings",
+	))
+	set(msgMeetingCount, plural.Selectf(1, "%d",
+		"one", "%d Meeting",
+		"other", "%d Meetings",
+	))
+}
@@ -1,16 +1,44 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/redis/go-redis/v9"
 	log "github.com/rs/zerolog/log"
 )
 
+// NameCacheTimeout bounds each Redis call caching guild & channel names, so a slow Redis cannot stall command logging
+const NameCacheTimeout = 500 * time.Millisecond
+
+// nameCacheContext returns a context for a single name cache Redis call, which must be cancelled once the call completes
+func nameCacheContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, NameCacheTimeout)
+}
+
+// NameLookupFailureTTL classifies a failed guild/channel lookup, returning how long the failure should be cached.
+// Permanent failures (missing permissions, unknown guild/channel) are cached much longer than transient ones (network, outages).
+func NameLookupFailureTTL(err error) (bool, time.Duration) {
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Response != nil {
+		switch restErr.Response.StatusCode {
+		case http.StatusForbidden, http.StatusNotFound:
+			return true, time.Hour * 24
+		}
+	}
+
+	return false, time.Minute
+}
+
 // GetGuildName returns the name of the guild with the given ID, utilizing Redis to cache the value
 func GetGuildName(guildID string) string {
 	// Check Redis for the guild name
-	guildName, err := kv.Get(ctx, "guild:"+guildID+":name").Result()
+	getCtx, cancel := nameCacheContext()
+	guildName, err := kv.Get(getCtx, GuildNameKey(guildID)).Result()
+	cancel()
 	if err != nil && err != redis.Nil {
 		log.Error().Stack().Err(err).Msg("Error getting guild name from Redis")
 		return "err"
@@ -21,13 +49,25 @@ func GetGuildName(guildID string) string {
 		return "unknown"
 	}
 
+	// Use the cached guild name
+	if guildName != "" {
+		return guildName
+	}
+
 	// If the guild name isn't in Redis, get it from Discord and cache it
 	guild, err := session.Guild(guildID)
 	if err != nil {
-		log.Error().Stack().Err(err).Msg("Error getting guild name")
+		// Store an invalid value in Redis so we don't keep trying to get the guild name
+		permanent, ttl := NameLookupFailureTTL(err)
+		if permanent {
+			log.Warn().Err(err).Str("guildID", guildID).Msg("Cannot access guild name")
+		} else {
+			log.Error().Stack().Err(err).Msg("Error getting guild name")
+		}
 
-		// Store an invalid value in Redis so we  don't keep trying to get the guild name
-		_, err := kv.Set(ctx, "guild:"+guildID+":name", "x", time.Minute*5).Result()
+		setCtx, cancel := nameCacheContext()
+		defer cancel()
+		_, err := kv.Set(setCtx, GuildNameKey(guildID), "x", ttl).Result()
 		if err != nil {
 			log.Error().Stack().Err(err).Msg("Error setting false guild name in Redis")
 		}
@@ -36,7 +76,12 @@ func GetGuildName(guildID string) string {
 	}
 
 	// Cache the guild name in Redis
-	kv.Set(ctx, "guild:"+guildID+":name", guild.Name, time.Hour*3)
+	setCtx, cancel := nameCacheContext()
+	defer cancel()
+	err = kv.Set(setCtx, GuildNameKey(guildID), guild.Name, time.Hour*3).Err()
+	if err != nil {
+		log.Warn().Err(err).Msg("Error caching guild name in Redis")
+	}
 
 	return guild.Name
 }
@@ -44,7 +89,9 @@ func GetGuildName(guildID string) string {
 // GetChannelName returns the name of the channel with the given ID, utilizing Redis to cache the value
 func GetChannelName(channelID string) string {
 	// Check Redis for the channel name
-	channelName, err := kv.Get(ctx, "channel:"+channelID+":name").Result()
+	getCtx, cancel := nameCacheContext()
+	channelName, err := kv.Get(getCtx, ChannelNameKey(channelID)).Result()
+	cancel()
 	if err != nil && err != redis.Nil {
 		log.Error().Stack().Err(err).Msg("Error getting channel name from Redis")
 		return "err"
@@ -55,13 +102,25 @@ func GetChannelName(channelID string) string {
 		return "unknown"
 	}
 
+	// Use the cached channel name
+	if channelName != "" {
+		return channelName
+	}
+
 	// If the channel name isn't in Redis, get it from Discord and cache it
 	channel, err := session.Channel(channelID)
 	if err != nil {
-		log.Error().Stack().Err(err).Msg("Error getting channel name")
+		// Store an invalid value in Redis so we don't keep trying to get the channel name
+		permanent, ttl := NameLookupFailureTTL(err)
+		if permanent {
+			log.Warn().Err(err).Str("channelID", channelID).Msg("Cannot access channel name")
+		} else {
+			log.Error().Stack().Err(err).Msg("Error getting channel name")
+		}
 
-		// Store an invalid value in Redis so we  don't keep trying to get the channel name
-		_, err := kv.Set(ctx, "channel:"+channelID+":name", "x", time.Minute*5).Result()
+		setCtx, cancel := nameCacheContext()
+		defer cancel()
+		_, err := kv.Set(setCtx, ChannelNameKey(channelID), "x", ttl).Result()
 		if err != nil {
 			log.Error().Stack().Err(err).Msg("Error setting false channel name in Redis")
 		}
@@ -70,7 +129,12 @@ func GetChannelName(channelID string) string {
 	}
 
 	// Cache the channel name in Redis
-	kv.Set(ctx, "channel:"+channelID+":name", channel.Name, time.Hour*3)
+	setCtx, cancel := nameCacheContext()
+	defer cancel()
+	err = kv.Set(setCtx, ChannelNameKey(channelID), channel.Name, time.Hour*3).Err()
+	if err != nil {
+		log.Warn().Err(err).Msg("Error caching channel name in Redis")
+	}
 
 	return channel.Name
 }
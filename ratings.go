@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	rmpGraphQLURL = "https://www.ratemyprofessors.com/graphql"
+	// The GraphQL API accepts the same static credentials used by the RateMyProfessors website
+	rmpAuthorization = "Basic dGVzdDp0ZXN0"
+	// UTSA's school ID (1516), encoded as a GraphQL node ID ("School-1516")
+	rmpSchoolID = "U2Nob29sLTE1MTY="
+	// How long a professor's rating is cached; professors without an entry are cached for less time
+	rmpCacheTTL        = 7 * 24 * time.Hour
+	rmpMissingCacheTTL = 24 * time.Hour
+)
+
+const rmpSearchQuery = `query SearchTeachers($text: String!, $schoolID: ID!) {
+	newSearch {
+		teachers(query: {text: $text, schoolID: $schoolID}, first: 1) {
+			edges {
+				node {
+					legacyId
+					firstName
+					lastName
+					avgRating
+					avgDifficulty
+					numRatings
+				}
+			}
+		}
+	}
+}`
+
+var rmpClient = &http.Client{Timeout: 5 * time.Second}
+
+// ProfessorRating is a professor's RateMyProfessors rating.
+// Found is false if the professor has no RateMyProfessors entry, which is cached to avoid repeated lookups.
+type ProfessorRating struct {
+	Found      bool    `json:"found"`
+	LegacyID   int     `json:"legacyId"`
+	Rating     float64 `json:"rating"`
+	Difficulty float64 `json:"difficulty"`
+	NumRatings int     `json:"numRatings"`
+}
+
+func (rating ProfessorRating) MarshalBinary() ([]byte, error) {
+	return json.Marshal(rating)
+}
+
+// URL returns the link to the professor's RateMyProfessors page
+func (rating ProfessorRating) URL() string {
+	return fmt.Sprintf("https://www.ratemyprofessors.com/professor/%d", rating.LegacyID)
+}
+
+// rmpSearchName converts a Banner display name ("Last, First") into the name RateMyProfessors expects ("First Last")
+func rmpSearchName(displayName string) string {
+	last, first, found := strings.Cut(displayName, ",")
+	if !found {
+		return strings.TrimSpace(displayName)
+	}
+
+	return strings.TrimSpace(first) + " " + strings.TrimSpace(last)
+}
+
+// GetProfessorRating looks up a professor's rating on RateMyProfessors, caching the result in Redis
+func GetProfessorRating(ctx context.Context, displayName string) (*ProfessorRating, error) {
+	name := rmpSearchName(displayName)
+	key := RatingKey(NormalizeInstructorName(displayName))
+
+	// Check for a cached rating
+	cached, err := kv.Get(ctx, key).Result()
+	if err == nil {
+		var rating ProfessorRating
+		err = json.Unmarshal([]byte(cached), &rating)
+		if err == nil {
+			return &rating, nil
+		}
+		log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("Failed to unmarshal cached rating")
+	} else if err != redis.Nil {
+		log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("Failed to get cached rating")
+	}
+
+	rating, err := fetchProfessorRating(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := rmpCacheTTL
+	if !rating.Found {
+		ttl = rmpMissingCacheTTL
+	}
+
+	err = kv.Set(ctx, key, rating, ttl).Err()
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("Failed to cache rating")
+	}
+
+	return rating, nil
+}
+
+// fetchProfessorRating queries the RateMyProfessors GraphQL API for the best match of the given name
+func fetchProfessorRating(ctx context.Context, name string) (*ProfessorRating, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"query": rmpSearchQuery,
+		"variables": map[string]string{
+			"text":     name,
+			"schoolID": rmpSchoolID,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rating query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", rmpGraphQLURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build rating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", rmpAuthorization)
+	AddUserAgent(req)
+
+	res, err := rmpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ratings: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("rating query failed with status code: %d", res.StatusCode)
+	}
+
+	var result struct {
+		Data struct {
+			NewSearch struct {
+				Teachers struct {
+					Edges []struct {
+						Node struct {
+							LegacyID      int     `json:"legacyId"`
+							FirstName     string  `json:"firstName"`
+							LastName      string  `json:"lastName"`
+							AvgRating     float64 `json:"avgRating"`
+							AvgDifficulty float64 `json:"avgDifficulty"`
+							NumRatings    int     `json:"numRatings"`
+						} `json:"node"`
+					} `json:"edges"`
+				} `json:"teachers"`
+			} `json:"newSearch"`
+		} `json:"data"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ratings: %w", err)
+	}
+
+	edges := result.Data.NewSearch.Teachers.Edges
+	if len(edges) == 0 {
+		return &ProfessorRating{Found: false}, nil
+	}
+
+	// The search is fuzzy, so only accept a match on the last name
+	node := edges[0].Node
+	if !strings.Contains(strings.ToLower(name), strings.ToLower(node.LastName)) {
+		return &ProfessorRating{Found: false}, nil
+	}
+
+	return &ProfessorRating{
+		Found:      true,
+		LegacyID:   node.LegacyID,
+		Rating:     node.AvgRating,
+		Difficulty: node.AvgDifficulty,
+		NumRatings: node.NumRatings,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// MaxRecentSearches is the number of searches remembered per user
+	MaxRecentSearches = 5
+	// RecentSearchTTL is how long a user's recent searches are kept after their last search
+	RecentSearchTTL = 14 * 24 * time.Hour
+)
+
+// RecentSearch is a /search invocation remembered for a user, storing the options so it can be run again
+type RecentSearch struct {
+	// A readable description of the search (Query.String())
+	Description string                                               `json:"description"`
+	Options     []*discordgo.ApplicationCommandInteractionDataOption `json:"options"`
+}
+
+func (search RecentSearch) MarshalBinary() ([]byte, error) {
+	return json.Marshal(search)
+}
+
+// IsRecordingSearches checks if the user has opted in to remembering their recent searches
+func IsRecordingSearches(ctx context.Context, userID string) (bool, error) {
+	count, err := kv.Exists(ctx, RecentOptInKey(userID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check recent search opt-in: %w", err)
+	}
+	return count > 0, nil
+}
+
+// SetRecordingSearches opts the user in or out of remembering their recent searches.
+// Opting out also clears any remembered searches.
+func SetRecordingSearches(ctx context.Context, userID string, enabled bool) error {
+	if enabled {
+		return kv.Set(ctx, RecentOptInKey(userID), 1, 0).Err()
+	}
+
+	return kv.Del(ctx, RecentOptInKey(userID), RecentKey(userID)).Err()
+}
+
+// RecordSearch remembers a search for the user (most recent first), if they have opted in
+func RecordSearch(ctx context.Context, userID string, search RecentSearch) error {
+	enabled, err := IsRecordingSearches(ctx, userID)
+	if err != nil || !enabled {
+		return err
+	}
+
+	key := RecentKey(userID)
+	pipe := kv.Pipeline()
+	pipe.LPush(ctx, key, search)
+	pipe.LTrim(ctx, key, 0, MaxRecentSearches-1)
+	pipe.Expire(ctx, key, RecentSearchTTL)
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to record search: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecentSearches returns the user's remembered searches, most recent first
+func GetRecentSearches(ctx context.Context, userID string) ([]RecentSearch, error) {
+	raw, err := kv.LRange(ctx, RecentKey(userID), 0, MaxRecentSearches-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent searches: %w", err)
+	}
+
+	searches := make([]RecentSearch, 0, len(raw))
+	for _, entry := range raw {
+		var search RecentSearch
+		err := json.Unmarshal([]byte(entry), &search)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal recent search: %w", err)
+		}
+		searches = append(searches, search)
+	}
+
+	return searches, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/cespare/xxhash/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+)
+
+// CommandHash returns a hash of the command definition, used to detect changes between restarts
+func CommandHash(cmd *discordgo.ApplicationCommand) (string, error) {
+	raw, err := json.Marshal(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal command %s: %w", cmd.Name, err)
+	}
+
+	return strconv.FormatUint(xxhash.Sum64(raw), 16), nil
+}
+
+// commandNamePattern matches the characters Discord allows in a command name
+var commandNamePattern = regexp.MustCompile(`^[-_\p{Ll}\p{N}]*$`)
+
+// ValidateCommandPrefix checks that the prefix still produces valid command names (lowercase, at most 32 characters) for every definition
+func ValidateCommandPrefix(prefix string, definitions []*discordgo.ApplicationCommand) error {
+	if !commandNamePattern.MatchString(prefix) {
+		return fmt.Errorf("command prefix %q may only contain lowercase letters, numbers, dashes & underscores", prefix)
+	}
+
+	for _, cmd := range definitions {
+		if name := prefix + cmd.Name; len([]rune(name)) > 32 {
+			return fmt.Errorf("command name %q is longer than 32 characters", name)
+		}
+	}
+	return nil
+}
+
+// PrefixCommands returns copies of the definitions with their names prefixed (e.g. "dev-search"), so several bots can share a guild.
+// The definitions are returned as-is if the prefix is empty.
+func PrefixCommands(definitions []*discordgo.ApplicationCommand, prefix string) []*discordgo.ApplicationCommand {
+	if prefix == "" {
+		return definitions
+	}
+
+	return lo.Map(definitions, func(cmd *discordgo.ApplicationCommand, _ int) *discordgo.ApplicationCommand {
+		prefixed := *cmd
+		prefixed.Name = prefix + cmd.Name
+		return &prefixed
+	})
+}
+
+// UnprefixedCommandName returns the name a command is defined under, removing the configured prefix from an invoked command's name
+func UnprefixedCommandName(name string) string {
+	return strings.TrimPrefix(name, commandPrefix)
+}
+
+// RegisterCommands registers the command definitions with Discord, skipping any that are unchanged since the last registration.
+// Hashes of the registered definitions are stored in Redis under commands:<guild> ("global" when guildTarget is empty).
+func RegisterCommands(ctx context.Context, session *discordgo.Session, guildTarget string, definitions []*discordgo.ApplicationCommand) error {
+	key := CommandsKey(guildTarget)
+
+	storedHashes, err := kv.HGetAll(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get command hashes: %w", err)
+	}
+
+	// Commands deleted outside of the bot must be registered again, regardless of their hash
+	existingCommands, err := session.ApplicationCommands(session.State.User.ID, guildTarget)
+	if err != nil {
+		return fmt.Errorf("failed to get existing commands: %w", err)
+	}
+
+	for _, cmd := range definitions {
+		hash, err := CommandHash(cmd)
+		if err != nil {
+			return err
+		}
+
+		existingCommand, found := lo.Find(existingCommands, func(existing *discordgo.ApplicationCommand) bool {
+			return existing.Name == cmd.Name
+		})
+
+		if found && storedHashes[cmd.Name] == hash {
+			log.Debug().Str("commandName", cmd.Name).Msg("Command unchanged, skipping registration")
+			continue
+		}
+
+		// Creating a command with an existing name overwrites it
+		newCommand, err := session.ApplicationCommandCreate(session.State.User.ID, guildTarget, cmd)
+		if err != nil {
+			return fmt.Errorf("failed to register command %s: %w", cmd.Name, err)
+		}
+
+		if !found {
+			log.Info().Str("commandName", newCommand.Name).Msg("Registered new command")
+		} else {
+			log.Info().Str("commandName", newCommand.Name).
+				Str("oldVersion", existingCommand.Version).Str("newVersion", newCommand.Version).
+				Msg("Command Updated")
+		}
+
+		err = kv.HSet(ctx, key, cmd.Name, hash).Err()
+		if err != nil {
+			return fmt.Errorf("failed to store command hash: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UnregisterStaleCommands deletes the commands registered to guildTarget ("" for global) that are not in keep.
+// Their stored hashes are removed as well, so they are registered again if re-added.
+func UnregisterStaleCommands(ctx context.Context, session *discordgo.Session, guildTarget string, keep []*discordgo.ApplicationCommand) error {
+	key := CommandsKey(guildTarget)
+
+	registeredCommands, err := session.ApplicationCommands(session.State.User.ID, guildTarget)
+	if err != nil {
+		return fmt.Errorf("failed to get registered commands: %w", err)
+	}
+
+	for _, cmd := range registeredCommands {
+		if lo.ContainsBy(keep, func(kept *discordgo.ApplicationCommand) bool { return kept.Name == cmd.Name }) {
+			continue
+		}
+
+		err := session.ApplicationCommandDelete(session.State.User.ID, guildTarget, cmd.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete stale command %s: %w", cmd.Name, err)
+		}
+
+		err = kv.HDel(ctx, key, cmd.Name).Err()
+		if err != nil {
+			return fmt.Errorf("failed to delete command hash: %w", err)
+		}
+
+		log.Info().Str("commandName", cmd.Name).Str("guild", guildTarget).Msg("Unregistered stale command")
+	}
+
+	return nil
+}
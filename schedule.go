@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strconv"
+	"time"
+)
+
+// Conflict is an overlap between the meetings of two courses
+type Conflict struct {
+	Day   time.Weekday
+	Start NaiveTime // The start of the overlap
+	End   NaiveTime // The end of the overlap
+}
+
+// meetingClock returns the start & end time of a meeting, or false if it has no valid scheduled time (e.g. online, arranged)
+func meetingClock(m MeetingTimeResponse) (NaiveTime, NaiveTime, bool) {
+	begin, err := strconv.ParseUint(m.MeetingTime.BeginTime, 10, 32)
+	if err != nil {
+		return NaiveTime{}, NaiveTime{}, false
+	}
+
+	end, err := strconv.ParseUint(m.MeetingTime.EndTime, 10, 32)
+	if err != nil {
+		return NaiveTime{}, NaiveTime{}, false
+	}
+
+	start, finish := ParseNaiveTime(begin), ParseNaiveTime(end)
+	if !start.Valid() || !finish.Valid() {
+		return NaiveTime{}, NaiveTime{}, false
+	}
+
+	return *start, *finish, true
+}
+
+// meetingWeekdays returns the days a meeting occurs on, including Sunday
+func meetingWeekdays(m MeetingTimeResponse) []time.Weekday {
+	mt := m.MeetingTime
+	days := []time.Weekday{}
+	for day, meets := range []bool{mt.Sunday, mt.Monday, mt.Tuesday, mt.Wednesday, mt.Thursday, mt.Friday, mt.Saturday} {
+		if meets {
+			days = append(days, time.Weekday(day))
+		}
+	}
+	return days
+}
+
+// datesOverlap checks if two meetings share any dates, for courses only running part of a term.
+// Meetings with unparsable dates are assumed to overlap.
+func datesOverlap(a MeetingTimeResponse, b MeetingTimeResponse) bool {
+	aStart, errAStart := time.Parse(layout, a.MeetingTime.StartDate)
+	aEnd, errAEnd := time.Parse(layout, a.MeetingTime.EndDate)
+	bStart, errBStart := time.Parse(layout, b.MeetingTime.StartDate)
+	bEnd, errBEnd := time.Parse(layout, b.MeetingTime.EndDate)
+	if errAStart != nil || errAEnd != nil || errBStart != nil || errBEnd != nil {
+		return true
+	}
+
+	return !aStart.After(bEnd) && !bStart.After(aEnd)
+}
+
+// ConflictsWith checks if the course meets at the same time as the other, returning every day & time their meetings overlap.
+// Meetings without a scheduled time never conflict, and meetings that are merely adjacent (one ends as the other starts) do not overlap.
+func (course *Course) ConflictsWith(other *Course) (bool, []Conflict) {
+	conflicts := []Conflict{}
+
+	for _, aMeeting := range course.MeetingsFaculty {
+		aStart, aEnd, ok := meetingClock(aMeeting)
+		if !ok {
+			continue
+		}
+
+		for _, bMeeting := range other.MeetingsFaculty {
+			bStart, bEnd, ok := meetingClock(bMeeting)
+			if !ok || !datesOverlap(aMeeting, bMeeting) {
+				continue
+			}
+
+			if !aStart.Before(bEnd) || !bStart.Before(aEnd) {
+				continue
+			}
+
+			start, end := aStart, aEnd
+			if bStart.After(start) {
+				start = bStart
+			}
+			if bEnd.Before(end) {
+				end = bEnd
+			}
+
+			bDays := meetingWeekdays(bMeeting)
+			for _, day := range meetingWeekdays(aMeeting) {
+				for _, other := range bDays {
+					if day == other {
+						conflicts = append(conflicts, Conflict{Day: day, Start: start, End: end})
+					}
+				}
+			}
+		}
+	}
+
+	return len(conflicts) > 0, conflicts
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// scheduleTestMeeting builds a meeting on the given days & times (e.g. "0930", "1045"), running for the spring term
+func scheduleTestMeeting(begin string, end string, days ...time.Weekday) MeetingTimeResponse {
+	meeting := MeetingTimeResponse{}
+	mt := &meeting.MeetingTime
+	mt.BeginTime = begin
+	mt.EndTime = end
+	mt.StartDate = "01/16/2024"
+	mt.EndDate = "05/10/2024"
+
+	for _, day := range days {
+		switch day {
+		case time.Sunday:
+			mt.Sunday = true
+		case time.Monday:
+			mt.Monday = true
+		case time.Tuesday:
+			mt.Tuesday = true
+		case time.Wednesday:
+			mt.Wednesday = true
+		case time.Thursday:
+			mt.Thursday = true
+		case time.Friday:
+			mt.Friday = true
+		case time.Saturday:
+			mt.Saturday = true
+		}
+	}
+
+	return meeting
+}
+
+// withDates changes the dates a meeting runs between (MM/DD/YYYY)
+func withDates(meeting MeetingTimeResponse, start string, end string) MeetingTimeResponse {
+	meeting.MeetingTime.StartDate = start
+	meeting.MeetingTime.EndDate = end
+	return meeting
+}
+
+func TestCourseConflictsWith(t *testing.T) {
+	cases := []struct {
+		name      string
+		a, b      MeetingTimeResponse
+		conflicts []Conflict
+	}{
+		{
+			"partial overlap",
+			scheduleTestMeeting("0930", "1045", time.Tuesday),
+			scheduleTestMeeting("1000", "1115", time.Tuesday),
+			[]Conflict{{Day: time.Tuesday, Start: NaiveTime{10, 0}, End: NaiveTime{10, 45}}},
+		},
+		{
+			"contained",
+			scheduleTestMeeting("0900", "1200", time.Friday),
+			scheduleTestMeeting("1000", "1050", time.Friday),
+			[]Conflict{{Day: time.Friday, Start: NaiveTime{10, 0}, End: NaiveTime{10, 50}}},
+		},
+		{
+			"adjacent",
+			scheduleTestMeeting("0900", "1015", time.Monday, time.Wednesday),
+			scheduleTestMeeting("1015", "1130", time.Monday, time.Wednesday),
+			[]Conflict{},
+		},
+		{
+			"different days",
+			scheduleTestMeeting("0930", "1045", time.Monday, time.Wednesday),
+			scheduleTestMeeting("0930", "1045", time.Tuesday, time.Thursday),
+			[]Conflict{},
+		},
+		{
+			"online",
+			scheduleTestMeeting("0930", "1045", time.Monday),
+			scheduleTestMeeting("", "", time.Monday),
+			[]Conflict{},
+		},
+		{
+			"online without days",
+			scheduleTestMeeting("0930", "1045", time.Monday),
+			scheduleTestMeeting("", ""),
+			[]Conflict{},
+		},
+		{
+			"separate date ranges",
+			withDates(scheduleTestMeeting("0930", "1045", time.Monday), "01/16/2024", "03/08/2024"),
+			withDates(scheduleTestMeeting("0930", "1045", time.Monday), "03/18/2024", "05/10/2024"),
+			[]Conflict{},
+		},
+		{
+			"overlapping date ranges",
+			withDates(scheduleTestMeeting("0930", "1045", time.Monday), "01/16/2024", "03/18/2024"),
+			withDates(scheduleTestMeeting("0930", "1045", time.Monday), "03/18/2024", "05/10/2024"),
+			[]Conflict{{Day: time.Monday, Start: NaiveTime{9, 30}, End: NaiveTime{10, 45}}},
+		},
+		{
+			"several shared days",
+			scheduleTestMeeting("1300", "1350", time.Monday, time.Wednesday, time.Friday),
+			scheduleTestMeeting("1330", "1445", time.Monday, time.Tuesday, time.Friday),
+			[]Conflict{
+				{Day: time.Monday, Start: NaiveTime{13, 30}, End: NaiveTime{13, 50}},
+				{Day: time.Friday, Start: NaiveTime{13, 30}, End: NaiveTime{13, 50}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		a := Course{MeetingsFaculty: []MeetingTimeResponse{c.a}}
+		b := Course{MeetingsFaculty: []MeetingTimeResponse{c.b}}
+
+		conflicting, conflicts := a.ConflictsWith(&b)
+		if conflicting != (len(c.conflicts) > 0) {
+			t.Errorf("%s: conflicting = %t, want %t", c.name, conflicting, len(c.conflicts) > 0)
+		}
+		if !reflect.DeepEqual(conflicts, c.conflicts) {
+			t.Errorf("%s: conflicts = %+v, want %+v", c.name, conflicts, c.conflicts)
+		}
+
+		// Conflicts are symmetric
+		if reverse, _ := b.ConflictsWith(&a); reverse != conflicting {
+			t.Errorf("%s: reversed conflicting = %t, want %t", c.name, reverse, conflicting)
+		}
+	}
+}
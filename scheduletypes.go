@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+)
+
+// scheduleTypeMethods maps the last two characters of a meeting schedule type code (e.g. AFF, BIN) to its method of delivery.
+// These were decoded by comparing the codes against each meeting's type; the leading character is not yet understood,
+// but appears to group sections by part of term.
+var scheduleTypeMethods = map[string]string{
+	"FF": "Face to Face",
+	"HB": "Hybrid",
+	"IN": "Internet",
+}
+
+// ScheduleTypeDescription decodes the meeting's schedule type code as far as it is understood (e.g. AFF => "Face to Face (group A)")
+func (m *MeetingTimeResponse) ScheduleTypeDescription() string {
+	code := m.MeetingTime.MeetingScheduleType
+	if len(code) != 3 {
+		return fmt.Sprintf("Unknown (%s)", code)
+	}
+
+	method, ok := scheduleTypeMethods[code[1:]]
+	if !ok {
+		method = fmt.Sprintf("Unknown method %s", code[1:])
+	}
+
+	return fmt.Sprintf("%s (group %c)", method, code[0])
+}
+
+// RecordScheduleTypes remembers every meeting schedule type code seen, logging codes that have not been seen before.
+// The recorded codes are the real world data needed to finish decoding them.
+func RecordScheduleTypes(ctx context.Context, course Course) error {
+	codes := lo.Uniq(lo.FilterMap(course.MeetingsFaculty, func(m MeetingTimeResponse, _ int) (string, bool) {
+		return m.MeetingTime.MeetingScheduleType, m.MeetingTime.MeetingScheduleType != ""
+	}))
+	if len(codes) == 0 {
+		return nil
+	}
+
+	pipe := kv.Pipeline()
+	added := lo.Map(codes, func(code string, _ int) *redis.IntCmd {
+		return pipe.SAdd(ctx, ScheduleTypesKey(), code)
+	})
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to record schedule types: %w", err)
+	}
+
+	for i, cmd := range added {
+		if cmd.Val() > 0 {
+			meeting, _ := lo.Find(course.MeetingsFaculty, func(m MeetingTimeResponse) bool {
+				return m.MeetingTime.MeetingScheduleType == codes[i]
+			})
+			log.Ctx(ctx).Info().Str("code", codes[i]).Str("crn", course.CourseReferenceNumber).Str("partOfTerm", course.PartOfTerm).
+				Str("meetingType", meeting.MeetingTime.MeetingType).Str("instructionalMethod", course.InstructionalMethod).Str("decoded", meeting.ScheduleTypeDescription()).Msg("New meeting schedule type seen")
+		}
+	}
+
+	return nil
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 	"github.com/samber/lo"
 )
@@ -23,11 +26,11 @@ var (
 )
 
 // Scrape is the general scraping invocation (best called within/as a goroutine) that should be called regularly to initiate scraping of the Banner system.
-func Scrape() error {
+func Scrape(ctx context.Context) error {
 	// Populate AllMajors if it is empty
 	if len(AncillaryMajors) == 0 {
 		term := Default(time.Now()).ToString()
-		subjects, err := GetSubjects("", term, 1, 99)
+		subjects, err := GetSubjects(ctx, "", term, 1, 99)
 		if err != nil {
 			return fmt.Errorf("failed to get subjects: %w", err)
 		}
@@ -48,14 +51,14 @@ func Scrape() error {
 		AllMajors = lo.Flatten([][]string{PriorityMajors, AncillaryMajors})
 	}
 
-	expiredSubjects, err := GetExpiredSubjects()
+	expiredSubjects, err := GetExpiredSubjects(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get scrapable majors: %w", err)
 	}
 
 	log.Info().Strs("majors", expiredSubjects).Msg("Scraping majors")
 	for _, subject := range expiredSubjects {
-		err := ScrapeMajor(subject)
+		err := ScrapeMajor(ctx, subject)
 		if err != nil {
 			return fmt.Errorf("failed to scrape major %s: %w", subject, err)
 		}
@@ -65,13 +68,32 @@ func Scrape() error {
 }
 
 // GetExpiredSubjects returns a list of subjects that are expired and should be scraped.
-func GetExpiredSubjects() ([]string, error) {
+func GetExpiredSubjects(ctx context.Context) ([]string, error) {
+	return FilterExpiredSubjects(ctx, ScrapableSubjects(AllMajors))
+}
+
+// ScrapableSubjects returns the given subjects allowed to be scraped by the allow & deny lists (SCRAPE_SUBJECTS_ALLOW, SCRAPE_SUBJECTS_DENY).
+// Every subject is scrapable when neither list is set.
+func ScrapableSubjects(subjects []string) []string {
+	return lo.Filter(subjects, func(subject string, _ int) bool {
+		if len(scrapeAllowSubjects) > 0 && !lo.Contains(scrapeAllowSubjects, subject) {
+			return false
+		}
+		return !lo.Contains(scrapeDenySubjects, subject)
+	})
+}
+
+// FilterExpiredSubjects returns the given subjects that are expired and should be scraped.
+func FilterExpiredSubjects(ctx context.Context, candidates []string) ([]string, error) {
 	term := Default(time.Now()).ToString()
 	subjects := make([]string, 0)
+	if len(candidates) == 0 {
+		return subjects, nil
+	}
 
 	// Get all subjects
-	values, err := kv.MGet(ctx, lo.Map(AllMajors, func(major string, _ int) string {
-		return fmt.Sprintf("scraped:%s:%s", major, term)
+	values, err := kv.MGet(ctx, lo.Map(candidates, func(major string, _ int) string {
+		return ScrapedKey(major, term)
 	})...).Result()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all subjects: %w", err)
@@ -79,7 +101,7 @@ func GetExpiredSubjects() ([]string, error) {
 
 	// Extract expired subjects
 	for i, value := range values {
-		subject := AllMajors[i]
+		subject := candidates[i]
 
 		// If the value is nil or "0", then the subject is expired
 		if value == nil || value == "0" {
@@ -92,16 +114,57 @@ func GetExpiredSubjects() ([]string, error) {
 	return subjects, nil
 }
 
+// WarmPriorityMajors scrapes any expired PriorityMajors, so the most used subjects are available soon after startup.
+// Scraping stops once the timeout is reached, leaving the remaining subjects to the periodic scrape.
+func WarmPriorityMajors(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	expiredSubjects, err := FilterExpiredSubjects(ctx, ScrapableSubjects(PriorityMajors))
+	if err != nil {
+		return fmt.Errorf("failed to get expired priority majors: %w", err)
+	}
+
+	log.Info().Strs("majors", expiredSubjects).Dur("timeout", timeout).Msg("Warming priority majors")
+	for _, subject := range expiredSubjects {
+		err := ScrapeMajor(ctx, subject)
+		if err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				log.Warn().Str("subject", subject).Msg("Priority major warming timed out")
+				return nil
+			}
+			return fmt.Errorf("failed to scrape major %s: %w", subject, err)
+		}
+	}
+
+	return nil
+}
+
+// NextScrapeOffset returns the offset of the page following a page of the given size, and whether another page should be requested.
+// The offset advances by the number of classes actually returned, so any page size Banner chooses is handled,
+// and more pages are requested until the total count reported by Banner is reached.
+func NextScrapeOffset(offset int, classCount int, totalCount int) (int, bool) {
+	// An empty page means there is nothing left, even if the total suggests otherwise
+	if classCount == 0 {
+		return offset, false
+	}
+
+	next := offset + classCount
+	return next, next < totalCount
+}
+
 // ScrapeMajor is the scraping invocation for a specific major.
 // This function does not check whether scraping is required at this time, it is assumed that the caller has already done so.
-func ScrapeMajor(subject string) error {
+func ScrapeMajor(ctx context.Context, subject string) error {
 	offset := 0
 	totalClassCount := 0
+	// CRNs already ingested this pass, as Banner's pages can overlap when classes are added mid-scrape
+	seen := make(map[string]bool)
 
 	for {
 		// Build & execute the query
-		query := NewQuery().Offset(offset).MaxResults(MaxPageSize * 2).Subject(subject)
-		result, err := Search(query, "subjectDescription", false)
+		query := NewQuery().Offset(offset).MaxResults(MaxPageSize).Subject(subject)
+		result, err := Search(ctx, query, "subjectDescription", false)
 		if err != nil {
 			return fmt.Errorf("search failed: %w (%s)", err, query.String())
 		}
@@ -111,30 +174,42 @@ func ScrapeMajor(subject string) error {
 			return fmt.Errorf("result marked unsuccessful when searching for classes (%s)", query.String())
 		}
 
+		// The offset advances by the classes actually returned, so mismatched paging is survivable, but worth knowing about
+		if err := result.CheckPaging(offset, MaxPageSize); err != nil {
+			log.Warn().Err(err).Str("subject", subject).Msg("Banner did not honor paging")
+		}
+
 		classCount := len(result.Data)
-		totalClassCount += classCount
 		log.Debug().Str("subject", subject).Int("count", classCount).Int("offset", offset).Msg("Placing classes in Redis")
 
 		// Process each class and store it in Redis
 		for _, course := range result.Data {
+			if seen[course.CourseReferenceNumber] {
+				log.Debug().Str("subject", subject).Str("crn", course.CourseReferenceNumber).Msg("Skipping duplicate class")
+				continue
+			}
+			seen[course.CourseReferenceNumber] = true
+			totalClassCount++
+
 			// Store class in Redis
-			err := IntakeCourse(course)
+			err := IntakeCourse(ctx, course)
 			if err != nil {
 				log.Error().Err(err).Msg("failed to store class in Redis")
 			}
 		}
 
-		// Increment and continue if the results are full
-		if classCount >= MaxPageSize {
-			// This is unlikely to happen, but log it just in case
-			if classCount > MaxPageSize {
-				log.Warn().Int("page", offset).Int("count", classCount).Msg("Results exceed MaxPageSize")
-			}
+		// Banner may return more or fewer classes than requested, which the offset accounts for
+		if classCount > MaxPageSize {
+			log.Debug().Int("offset", offset).Int("count", classCount).Msg("Results exceed MaxPageSize")
+		}
 
-			offset += MaxPageSize
+		// Continue until every class has been fetched
+		nextOffset, more := NextScrapeOffset(offset, classCount, result.TotalCount)
+		if more {
+			offset = nextOffset
 
 			// TODO: Replace sleep with smarter rate limiting
-			log.Debug().Str("subject", subject).Int("nextOffset", offset).Msg("Sleeping before next page")
+			log.Debug().Str("subject", subject).Int("nextOffset", offset).Int("total", result.TotalCount).Msg("Sleeping before next page")
 			time.Sleep(time.Second * 3)
 			continue
 		} else {
@@ -149,16 +224,22 @@ func ScrapeMajor(subject string) error {
 	// Calculate the expiry time for the scrape (1 hour for every 200 classes, random +-15%) with a minimum of 1 hour
 	var scrapeExpiry time.Duration
 	if totalClassCount == 0 {
-		scrapeExpiry = time.Hour * 12
+		scrapeExpiry = EmptyScrapeExpiry(ctx, subject, term)
 	} else {
-		scrapeExpiry = CalculateExpiry(term, totalClassCount, lo.Contains(PriorityMajors, subject))
+		scrapeExpiry = CalculateExpiry(ctx, term, totalClassCount, lo.Contains(PriorityMajors, subject))
+
+		// The subject has classes again, so any backoff starts over
+		err := kv.Del(ctx, EmptyScrapesKey(subject, term)).Err()
+		if err != nil {
+			log.Error().Err(err).Str("subject", subject).Msg("failed to reset empty scrape count")
+		}
 	}
 
 	// Mark the major as scraped
 	if totalClassCount == 0 {
 		totalClassCount = -1
 	}
-	err := kv.Set(ctx, fmt.Sprintf("scraped:%s:%s", subject, term), totalClassCount, scrapeExpiry).Err()
+	err := kv.Set(ctx, ScrapedKey(subject, term), totalClassCount, scrapeExpiry).Err()
 	if err != nil {
 		log.Error().Err(err).Msg("failed to mark major as scraped")
 	}
@@ -166,11 +247,53 @@ func ScrapeMajor(subject string) error {
 	return nil
 }
 
+// RescrapeMajor clears the scrape marker for a major and scrapes it immediately, regardless of whether it has expired.
+func RescrapeMajor(ctx context.Context, subject string) error {
+	term := Default(time.Now()).ToString()
+
+	err := kv.Del(ctx, ScrapedKey(subject, term)).Err()
+	if err != nil {
+		return fmt.Errorf("failed to clear scrape marker: %w", err)
+	}
+
+	return ScrapeMajor(ctx, subject)
+}
+
+const (
+	// EmptyScrapeBaseExpiry is the expiry after a subject is first found without any classes
+	EmptyScrapeBaseExpiry = 12 * time.Hour
+	// EmptyScrapeMaxExpiry caps the expiry of subjects repeatedly found without any classes
+	EmptyScrapeMaxExpiry = 7 * 24 * time.Hour
+)
+
+// EmptyScrapeExpiry records another scrape of the subject finding no classes, and returns the expiry until the next scrape.
+// Many subjects never offer classes in a given term, so the expiry doubles with each consecutive empty scrape, up to EmptyScrapeMaxExpiry.
+func EmptyScrapeExpiry(ctx context.Context, subject string, term string) time.Duration {
+	key := EmptyScrapesKey(subject, term)
+	count, err := kv.Incr(ctx, key).Result()
+	if err != nil {
+		log.Error().Err(err).Str("subject", subject).Msg("failed to count empty scrapes")
+		return EmptyScrapeBaseExpiry
+	}
+
+	// Forget the count eventually, in case the subject is never scraped again
+	kv.Expire(ctx, key, 2*EmptyScrapeMaxExpiry)
+
+	expiry := EmptyScrapeBaseExpiry
+	for i := int64(1); i < count && expiry < EmptyScrapeMaxExpiry; i++ {
+		expiry *= 2
+	}
+	expiry = min(expiry, EmptyScrapeMaxExpiry)
+
+	log.Debug().Str("subject", subject).Int64("consecutive", count).Dur("expiry", expiry).Msg("Subject has no classes")
+	return expiry
+}
+
 // CalculateExpiry calculates the expiry time until the next scrape for a major.
 // term is the term for which the relevant course is occurring within.
 // count is the number of courses that were scraped.
 // priority is a boolean indicating whether the major is a priority major.
-func CalculateExpiry(term string, count int, priority bool) time.Duration {
+func CalculateExpiry(ctx context.Context, term string, count int, priority bool) time.Duration {
 	// An hour for every 100 classes
 	baseExpiry := time.Hour * time.Duration(count/100)
 
@@ -188,7 +311,7 @@ func CalculateExpiry(term string, count int, priority bool) time.Duration {
 
 	// If the term is considered "view only" or "archived", then the expiry is multiplied by 5
 	var expiry = baseExpiry
-	if IsTermArchived(term) {
+	if IsTermArchived(ctx, term) {
 		expiry *= 5
 	}
 
@@ -210,10 +333,51 @@ func CalculateExpiry(term string, count int, priority bool) time.Duration {
 
 // IntakeCourse stores a course in Redis.
 // This function is mostly a stub for now, but will be used to handle change identification, notifications, and SQLite upserts in the future.
-func IntakeCourse(course Course) error {
-	err := kv.Set(ctx, fmt.Sprintf("class:%s", course.CourseReferenceNumber), course, 0).Err()
+func IntakeCourse(ctx context.Context, course Course) error {
+	// Record any changes from the previously scraped version
+	previous, err := GetCourse(ctx, course.CourseReferenceNumber)
+	if err == nil {
+		err = RecordChanges(ctx, course.Term, DiffCourses(*previous, course, time.Now()))
+		if err != nil {
+			return err
+		}
+
+		err = BufferOpenedSection(ctx, *previous, course)
+		if err != nil {
+			return err
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to get previous class: %w", err)
+	}
+
+	err = kv.Set(ctx, ClassKey(course.CourseReferenceNumber), course, 0).Err()
 	if err != nil {
 		return fmt.Errorf("failed to store class in Redis: %w", err)
 	}
+
+	// Index the buildings this course meets in
+	err = IndexBuildings(ctx, course)
+	if err != nil {
+		return fmt.Errorf("failed to index buildings: %w", err)
+	}
+
+	// Index the course under its subject
+	err = IndexSubject(ctx, course)
+	if err != nil {
+		return fmt.Errorf("failed to index subject: %w", err)
+	}
+
+	// Index the instructors teaching this course
+	err = IndexInstructors(ctx, course)
+	if err != nil {
+		return fmt.Errorf("failed to index instructors: %w", err)
+	}
+
+	// Remember the schedule types seen, so they can be decoded
+	err = RecordScheduleTypes(ctx, course)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestNextScrapeOffset(t *testing.T) {
+	cases := []struct {
+		offset, classCount, totalCount int
+		next                           int
+		more                           bool
+	}{
+		{0, 500, 1200, 500, true},
+		{500, 500, 1200, 1000, true},
+		{1000, 200, 1200, 1200, false},
+		{0, 500, 500, 500, false},
+		{0, 0, 0, 0, false},
+		// Banner returned fewer classes than requested, so the next page starts right after them
+		{0, 100, 1200, 100, true},
+		// An empty page ends the scrape, even if the total is wrong
+		{500, 0, 1200, 500, false},
+	}
+
+	for _, c := range cases {
+		next, more := NextScrapeOffset(c.offset, c.classCount, c.totalCount)
+		if next != c.next || more != c.more {
+			t.Errorf("NextScrapeOffset(%d, %d, %d) = (%d, %t), want (%d, %t)", c.offset, c.classCount, c.totalCount, next, more, c.next, c.more)
+		}
+	}
+}
+
+// fakePagedSearch mimics Banner's paging over total classes, returning at most pageSize classes regardless of the size requested
+func fakePagedSearch(total int, pageSize int) func(offset int, maxResults int) *SearchResult {
+	return func(offset int, maxResults int) *SearchResult {
+		size := min(maxResults, pageSize)
+		end := min(offset+size, total)
+
+		result := &SearchResult{Success: true, TotalCount: total, PageOffset: offset, PageMaxSize: size}
+		for i := offset; i < end; i++ {
+			result.Data = append(result.Data, Course{CourseReferenceNumber: strconv.Itoa(10000 + i)})
+		}
+		return result
+	}
+}
+
+func TestScrapePagination(t *testing.T) {
+	cases := []struct {
+		name     string
+		total    int
+		pageSize int
+		requests int
+	}{
+		{"empty subject", 0, MaxPageSize, 1},
+		{"single partial page", 120, MaxPageSize, 1},
+		{"exactly one page", MaxPageSize, MaxPageSize, 1},
+		{"several pages", 1234, MaxPageSize, 3},
+		{"smaller pages than requested", 1234, 100, 13},
+		{"pages of one", 5, 1, 5},
+	}
+
+	for _, c := range cases {
+		search := fakePagedSearch(c.total, c.pageSize)
+		seen := map[string]bool{}
+		offset, requests := 0, 0
+
+		for {
+			requests++
+			if requests > c.total+1 {
+				t.Fatalf("%s: pagination did not terminate", c.name)
+			}
+
+			result := search(offset, MaxPageSize)
+			for _, course := range result.Data {
+				if seen[course.CourseReferenceNumber] {
+					t.Errorf("%s: class %s fetched twice", c.name, course.CourseReferenceNumber)
+				}
+				seen[course.CourseReferenceNumber] = true
+			}
+
+			next, more := NextScrapeOffset(offset, len(result.Data), result.TotalCount)
+			if !more {
+				break
+			}
+			offset = next
+		}
+
+		if len(seen) != c.total {
+			t.Errorf("%s: fetched %d classes, want %d", c.name, len(seen), c.total)
+		}
+		if requests != c.requests {
+			t.Errorf("%s: made %d requests, want %d", c.name, requests, c.requests)
+		}
+	}
+}
@@ -1,18 +1,23 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/samber/lo"
 )
 
 const (
 	paramSubject           = "txt_subject"
-	paramTitle             = "txt_courseTitle"
-	paramKeywords          = "txt_keywordlike"
+	paramTitle             = "txt_courseTitle" // Banner ignores unknown parameters, so "txt_title" silently matches every course
+	paramKeywords          = "txt_keywordlike" // Matches courses containing the keywords (substring, "like" semantics)
+	paramKeywordExact      = "txt_keyword"     // Matches courses containing the exact phrase
 	paramOpenOnly          = "chk_open_only"
 	paramTermPart          = "txt_partOfTerm"
 	paramCampus            = "txt_campus"
@@ -33,9 +38,11 @@ const (
 )
 
 type Query struct {
-	subject             *string
+	term                *string   // e.g. 202510, the default term if unset
+	subjects            *[]string // e.g. [CS, MAT]
 	title               *string
 	keywords            *[]string
+	keywordExact        *string
 	openOnly            *bool
 	termPart            *[]string // e.g. [1, B6, 8, J]
 	campus              *[]string // e.g. [9, 1DT, 1LR]
@@ -55,9 +62,28 @@ func NewQuery() *Query {
 	return &Query{maxResults: 8, offset: 0}
 }
 
-// Subject sets the subject for the query
+// Term sets the term searched, instead of the default term
+func (q *Query) Term(term string) *Query {
+	q.term = &term
+	return q
+}
+
+// TermCode returns the term searched, which is the default term unless set
+func (q *Query) TermCode() string {
+	if q.term != nil {
+		return *q.term
+	}
+	return Default(time.Now()).ToString()
+}
+
+// Subject sets a single subject for the query
 func (q *Query) Subject(subject string) *Query {
-	q.subject = &subject
+	return q.Subjects([]string{subject})
+}
+
+// Subjects sets the subjects for the query, matching courses in any of them
+func (q *Query) Subjects(subjects []string) *Query {
+	q.subjects = &subjects
 	return q
 }
 
@@ -73,6 +99,12 @@ func (q *Query) Keywords(keywords []string) *Query {
 	return q
 }
 
+// KeywordExact sets an exact phrase the course must contain, unlike Keywords which match loosely
+func (q *Query) KeywordExact(phrase string) *Query {
+	q.keywordExact = &phrase
+	return q
+}
+
 // Keyword adds a keyword to the query
 func (q *Query) Keyword(keyword string) *Query {
 	if q.keywords == nil {
@@ -158,11 +190,201 @@ func (q *Query) MaxResults(maxResults int) *Query {
 	return q
 }
 
+// ParseKeywords splits a keyword string on whitespace, keeping "quoted phrases" together as a single keyword.
+// Empty keywords (e.g. from repeated spaces or empty quotes) are dropped. An unterminated quote extends to the end of the string.
+func ParseKeywords(raw string) []string {
+	keywords := []string{}
+	var current strings.Builder
+	quoted := false
+
+	flush := func() {
+		keyword := strings.TrimSpace(current.String())
+		if keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+		current.Reset()
+	}
+
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			// Quotes always start or end a keyword
+			flush()
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return keywords
+}
+
+// QuoteKeywords wraps any multi-word keywords in quotes, so phrases survive being joined by spaces
+func QuoteKeywords(keywords []string) []string {
+	return lo.Map(keywords, func(keyword string, _ int) string {
+		if strings.ContainsFunc(keyword, unicode.IsSpace) {
+			return `"` + keyword + `"`
+		}
+		return keyword
+	})
+}
+
 type Range struct {
 	Low  int
 	High int
 }
 
+var (
+	courseCodeRangePattern = regexp.MustCompile(`^(\d{1,4})?-(\d{1,4})?$`)
+	courseCodeWildPattern  = regexp.MustCompile(`^\d+[xX]+$`)
+)
+
+// ParseCourseCodeRange parses a course code or range of course codes into an inclusive low and high.
+// Accepts a single code (3743), a range (3000-3999), an open-ended range (3000-, -3999), or x's (3xxx, 3xx).
+func ParseCourseCodeRange(raw string) (int, int, error) {
+	var (
+		low  = -1
+		high = -1
+		// Codes below 1000 are only reachable through x's (3xx -> 300-399)
+		minimum = 1000
+	)
+	var err error
+	valueRaw := strings.TrimSpace(raw)
+
+	// Partially/fully specified range
+	if strings.Contains(valueRaw, "-") {
+		match := courseCodeRangePattern.FindStringSubmatch(valueRaw)
+		if match == nil || (match[1] == "" && match[2] == "") {
+			return 0, 0, NewUserError("invalid range format: %s", valueRaw)
+		}
+
+		// If there's not a low value, set it to min (open ended)
+		if match[1] == "" {
+			low = 1000
+		} else {
+			low, err = strconv.Atoi(match[1])
+			if err != nil {
+				return 0, 0, fmt.Errorf("error parsing course code (low): %w", err)
+			}
+		}
+
+		// If there's not a high value, set it to max (open ended)
+		if match[2] == "" {
+			high = 9999
+		} else {
+			high, err = strconv.Atoi(match[2])
+			if err != nil {
+				return 0, 0, fmt.Errorf("error parsing course code (high): %w", err)
+			}
+		}
+	}
+
+	// Digits followed by x's of any length (34xx -> 3400-3499, 3xx -> 300-399)
+	if strings.ContainsAny(valueRaw, "xX") {
+		if !courseCodeWildPattern.MatchString(valueRaw) {
+			return 0, 0, NewUserError("code range format invalid: must be 1 or more digits followed by x's (%s)", valueRaw)
+		}
+
+		// Replace x's with 0's
+		low, err = strconv.Atoi(strings.NewReplacer("x", "0", "X", "0").Replace(valueRaw))
+		if err != nil {
+			return 0, 0, fmt.Errorf("error parsing implied course code (low): %w", err)
+		}
+
+		// Replace x's with 9's
+		high, err = strconv.Atoi(strings.NewReplacer("x", "9", "X", "9").Replace(valueRaw))
+		if err != nil {
+			return 0, 0, fmt.Errorf("error parsing implied course code (high): %w", err)
+		}
+
+		minimum = 0
+	} else if len(valueRaw) == 4 {
+		// 4 digit code
+		low, err = strconv.Atoi(valueRaw)
+		if err != nil {
+			return 0, 0, NewUserError("course code invalid (%s)", valueRaw)
+		}
+
+		high = low
+	}
+
+	if low == -1 || high == -1 {
+		return 0, 0, NewUserError("course code range invalid (%s)", valueRaw)
+	}
+
+	if low > high {
+		return 0, 0, NewUserError("course code range is invalid: low is greater than high (%d > %d)", low, high)
+	}
+
+	if low < minimum || high < minimum || low > 9999 || high > 9999 {
+		return 0, 0, NewUserError("course code range is invalid: must be %d-9999 (%d-%d)", minimum, low, high)
+	}
+
+	return low, high, nil
+}
+
+// dayLetters maps the conventional single letter day abbreviations to weekdays (R is Thursday, U is Sunday)
+var dayLetters = map[rune]time.Weekday{
+	'U': time.Sunday,
+	'M': time.Monday,
+	'T': time.Tuesday,
+	'W': time.Wednesday,
+	'R': time.Thursday,
+	'F': time.Friday,
+	'S': time.Saturday,
+}
+
+// ParseDays parses a string of day letters (e.g. "MW", "TR") into a set of weekdays
+func ParseDays(raw string) (map[time.Weekday]bool, error) {
+	days := map[time.Weekday]bool{}
+	for _, letter := range strings.ToUpper(strings.TrimSpace(raw)) {
+		day, ok := dayLetters[letter]
+		if !ok {
+			return nil, NewUserError("invalid day '%c', use M, T, W, R, F, S, or U (%s)", letter, raw)
+		}
+		days[day] = true
+	}
+
+	if len(days) == 0 {
+		return nil, NewUserError("no days given")
+	}
+
+	return days, nil
+}
+
+// MeetsOnlyOn checks if every meeting of the course falls on the given days.
+// Courses without any meeting days (e.g. asynchronous online courses) never match.
+func MeetsOnlyOn(course Course, days map[time.Weekday]bool) bool {
+	meetsAtAll := false
+	for _, meeting := range course.MeetingsFaculty {
+		mt := meeting.MeetingTime
+		meetingDays := map[time.Weekday]bool{
+			time.Sunday:    mt.Sunday,
+			time.Monday:    mt.Monday,
+			time.Tuesday:   mt.Tuesday,
+			time.Wednesday: mt.Wednesday,
+			time.Thursday:  mt.Thursday,
+			time.Friday:    mt.Friday,
+			time.Saturday:  mt.Saturday,
+		}
+
+		for day, meets := range meetingDays {
+			if !meets {
+				continue
+			}
+			if !days[day] {
+				return false
+			}
+			meetsAtAll = true
+		}
+	}
+
+	return meetsAtAll
+}
+
 // FormatTimeParameter formats a time.Duration into a tuple of strings
 // This is mostly a private helper to keep the parameter formatting for both the start and end time consistent together
 func FormatTimeParameter(d time.Duration) (string, string, string) {
@@ -174,7 +396,7 @@ func FormatTimeParameter(d time.Duration) (string, string, string) {
 	minuteParameter = strconv.FormatInt(minutes, 10)
 
 	if hours >= 12 {
-		hourParameter = "PM"
+		meridiemParameter = "PM"
 
 		// Exceptional case: 12PM = 12, 1PM = 1, 2PM = 2
 		if hours >= 13 {
@@ -195,8 +417,8 @@ func FormatTimeParameter(d time.Duration) (string, string, string) {
 func (q *Query) Paramify() map[string]string {
 	params := map[string]string{}
 
-	if q.subject != nil {
-		params[paramSubject] = *q.subject
+	if q.subjects != nil {
+		params[paramSubject] = strings.Join(*q.subjects, ",")
 	}
 
 	if q.title != nil {
@@ -205,7 +427,11 @@ func (q *Query) Paramify() map[string]string {
 	}
 
 	if q.keywords != nil {
-		params[paramKeywords] = strings.Join(*q.keywords, " ")
+		params[paramKeywords] = strings.Join(QuoteKeywords(*q.keywords), " ")
+	}
+
+	if q.keywordExact != nil {
+		params[paramKeywordExact] = strings.TrimSpace(*q.keywordExact)
 	}
 
 	if q.openOnly != nil {
@@ -263,21 +489,82 @@ func (q *Query) Paramify() map[string]string {
 	return params
 }
 
+// Matches checks if the course satisfies the query's filters, for searching scraped courses without Banner.
+// Only the filters answerable from a course are applied; keywords are matched against the title, as descriptions are not scraped.
+func (q *Query) Matches(course Course) bool {
+	title := strings.ToLower(course.CourseTitle)
+
+	if q.subjects != nil && !lo.Contains(*q.subjects, course.Subject) {
+		return false
+	}
+
+	if q.title != nil && !strings.Contains(title, strings.ToLower(strings.TrimSpace(*q.title))) {
+		return false
+	}
+
+	if q.keywords != nil && !lo.EveryBy(*q.keywords, func(keyword string) bool {
+		return strings.Contains(title, strings.ToLower(keyword))
+	}) {
+		return false
+	}
+
+	if q.keywordExact != nil && !strings.Contains(title, strings.ToLower(strings.TrimSpace(*q.keywordExact))) {
+		return false
+	}
+
+	if q.openOnly != nil && *q.openOnly && !course.OpenSection {
+		return false
+	}
+
+	if q.attributes != nil && !lo.SomeBy(course.SectionAttributes, func(attribute SectionAttribute) bool {
+		return lo.Contains(*q.attributes, attribute.Code)
+	}) {
+		return false
+	}
+
+	// Variable credit courses match if any of their credit hours are within the range
+	low, high := course.CreditRange()
+	if q.minCredits != nil && high < *q.minCredits {
+		return false
+	}
+	if q.maxCredits != nil && low > *q.maxCredits {
+		return false
+	}
+
+	if q.courseNumberRange != nil {
+		number, err := strconv.Atoi(course.CourseNumber)
+		if err != nil || number < q.courseNumberRange.Low || number > q.courseNumberRange.High {
+			return false
+		}
+	}
+
+	return true
+}
+
 // String returns a string representation of the query, ideal for debugging & logging.
+// Free-form values are quoted, so the representation can be parsed back with ParseQuery.
 func (q *Query) String() string {
 	var sb strings.Builder
 
-	if q.subject != nil {
-		fmt.Fprintf(&sb, "subject=%s, ", *q.subject)
+	if q.term != nil {
+		fmt.Fprintf(&sb, "term=%s, ", *q.term)
+	}
+
+	if q.subjects != nil {
+		fmt.Fprintf(&sb, "subject=%s, ", strings.Join(*q.subjects, ","))
 	}
 
 	if q.title != nil {
 		// Whitespace can prevent valid queries from succeeding
-		fmt.Fprintf(&sb, "title=%s, ", strings.TrimSpace(*q.title))
+		fmt.Fprintf(&sb, "title=%q, ", strings.TrimSpace(*q.title))
 	}
 
 	if q.keywords != nil {
-		fmt.Fprintf(&sb, "keywords=%s, ", strings.Join(*q.keywords, " "))
+		fmt.Fprintf(&sb, "keywords=%q, ", strings.Join(QuoteKeywords(*q.keywords), " "))
+	}
+
+	if q.keywordExact != nil {
+		fmt.Fprintf(&sb, "keywordExact=%q, ", strings.TrimSpace(*q.keywordExact))
 	}
 
 	if q.openOnly != nil {
@@ -292,6 +579,10 @@ func (q *Query) String() string {
 		fmt.Fprintf(&sb, "campus=%s, ", strings.Join(*q.campus, ","))
 	}
 
+	if q.instructionalMethod != nil {
+		fmt.Fprintf(&sb, "instructionalMethod=%s, ", strings.Join(*q.instructionalMethod, ","))
+	}
+
 	if q.attributes != nil {
 		fmt.Fprintf(&sb, "attributes=%s, ", strings.Join(*q.attributes, ","))
 	}
@@ -303,13 +594,11 @@ func (q *Query) String() string {
 	}
 
 	if q.startTime != nil {
-		hour, minute, meridiem := FormatTimeParameter(*q.startTime)
-		fmt.Fprintf(&sb, "startTime=%s:%s%s, ", hour, minute, meridiem)
+		fmt.Fprintf(&sb, "startTime=%s, ", *q.startTime)
 	}
 
 	if q.endTime != nil {
-		hour, minute, meridiem := FormatTimeParameter(*q.endTime)
-		fmt.Fprintf(&sb, "endTime=%s:%s%s, ", hour, minute, meridiem)
+		fmt.Fprintf(&sb, "endTime=%s, ", *q.endTime)
 	}
 
 	if q.minCredits != nil {
@@ -330,6 +619,131 @@ func (q *Query) String() string {
 	return sb.String()
 }
 
+// Key returns a short, stable identifier for the query, suitable for cache keys.
+// Queries differing only by case produce the same key, as Banner searches are case-insensitive.
+func (q *Query) Key() string {
+	hash := sha256.Sum256([]byte(strings.ToLower(q.String())))
+	return hex.EncodeToString(hash[:8])
+}
+
+// ParseQuery reconstructs a query from its String representation
+func ParseQuery(raw string) (*Query, error) {
+	q := NewQuery()
+	rest := raw
+
+	splitList := func(value string) []string {
+		return strings.Split(value, ",")
+	}
+
+	for rest != "" {
+		key, after, found := strings.Cut(rest, "=")
+		if !found {
+			return nil, fmt.Errorf("missing value for %q", rest)
+		}
+
+		// Quoted values may contain separators, so they are read as a whole
+		var value string
+		if strings.HasPrefix(after, `"`) {
+			quoted, err := strconv.QuotedPrefix(after)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value for %s: %w", key, err)
+			}
+			value, _ = strconv.Unquote(quoted)
+
+			rest = after[len(quoted):]
+			if rest != "" {
+				var separated bool
+				rest, separated = strings.CutPrefix(rest, ", ")
+				if !separated {
+					return nil, fmt.Errorf("expected separator after %s", key)
+				}
+			}
+		} else {
+			value, rest, _ = strings.Cut(after, ", ")
+		}
+
+		var err error
+		switch key {
+		case "term":
+			if !IsValidTermCode(value) {
+				err = fmt.Errorf("invalid term code %q", value)
+			}
+			q.Term(value)
+		case "subject":
+			q.Subjects(splitList(value))
+		case "title":
+			q.Title(value)
+		case "keywords":
+			q.Keywords(ParseKeywords(value))
+		case "keywordExact":
+			q.KeywordExact(value)
+		case "openOnly":
+			var openOnly bool
+			openOnly, err = strconv.ParseBool(value)
+			q.OpenOnly(openOnly)
+		case "termPart":
+			q.TermPart(splitList(value))
+		case "campus":
+			q.Campus(splitList(value))
+		case "instructionalMethod":
+			q.InstructionalMethod(splitList(value))
+		case "attributes":
+			q.Attributes(splitList(value))
+		case "instructor":
+			instructors := []uint64{}
+			for _, id := range splitList(value) {
+				var parsed uint64
+				parsed, err = strconv.ParseUint(id, 10, 64)
+				if err != nil {
+					break
+				}
+				instructors = append(instructors, parsed)
+			}
+			q.Instructor(instructors)
+		case "startTime", "endTime":
+			var d time.Duration
+			d, err = time.ParseDuration(value)
+			if key == "startTime" {
+				q.StartTime(d)
+			} else {
+				q.EndTime(d)
+			}
+		case "minCredits", "maxCredits":
+			var credits int
+			credits, err = strconv.Atoi(value)
+			if key == "minCredits" {
+				q.MinCredits(credits)
+			} else {
+				q.MaxCredits(credits)
+			}
+		case "courseNumberRange":
+			low, high, _ := strings.Cut(value, "-")
+			var lowValue, highValue int
+			lowValue, err = strconv.Atoi(low)
+			if err == nil {
+				highValue, err = strconv.Atoi(high)
+			}
+			q.CourseNumbers(lowValue, highValue)
+		case "offset":
+			var offset int
+			offset, err = strconv.Atoi(value)
+			q.Offset(offset)
+		case "maxResults":
+			var maxResults int
+			maxResults, err = strconv.Atoi(value)
+			q.MaxResults(maxResults)
+		default:
+			return nil, fmt.Errorf("unknown query field %q", key)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+	}
+
+	return q, nil
+}
+
 // Dict returns a map representation of the query, ideal for debugging & logging.
 // This dict is represented with zerolog's Event type.
 // func (q *Query) Dict() *zerolog.Event {
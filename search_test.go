@@ -0,0 +1,188 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseCourseCodeRange(t *testing.T) {
+	cases := []struct {
+		raw       string
+		low, high int
+		valid     bool
+	}{
+		{"3743", 3743, 3743, true},
+		{" 3743 ", 3743, 3743, true},
+		{"3000-3999", 3000, 3999, true},
+		{"3000-3000", 3000, 3000, true},
+		{"3000-", 3000, 9999, true},
+		{"-3999", 1000, 3999, true},
+		{"3xxx", 3000, 3999, true},
+		{"34XX", 3400, 3499, true},
+		{"3xx", 300, 399, true},
+		// x's can reach below the usual minimum of 1000
+		{"0xx", 0, 99, true},
+		{"0x", 0, 9, true},
+		{"-", 0, 0, false},
+		{"", 0, 0, false},
+		{"xx", 0, 0, false},
+		{"x3", 0, 0, false},
+		{"3x3x", 0, 0, false},
+		{"3xxxx", 0, 0, false},
+		{"999", 0, 0, false},
+		{"0999", 0, 0, false},
+		{"99999", 0, 0, false},
+		{"12345-", 0, 0, false},
+		{"3999-3000", 0, 0, false},
+		{"0-999", 0, 0, false},
+		{"3000--3999", 0, 0, false},
+		{"abcd", 0, 0, false},
+	}
+
+	for _, c := range cases {
+		low, high, err := ParseCourseCodeRange(c.raw)
+		if !c.valid {
+			if err == nil {
+				t.Errorf("ParseCourseCodeRange(%q) = (%d, %d), want an error", c.raw, low, high)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseCourseCodeRange(%q) failed: %v", c.raw, err)
+		} else if low != c.low || high != c.high {
+			t.Errorf("ParseCourseCodeRange(%q) = (%d, %d), want (%d, %d)", c.raw, low, high, c.low, c.high)
+		}
+	}
+}
+
+func TestParamify(t *testing.T) {
+	query := NewQuery().
+		Subjects([]string{"CS", "MAT"}).
+		Title(" Data Structures ").
+		Keywords([]string{"intro", "machine learning"}).
+		KeywordExact("neural networks").
+		OpenOnly(true).
+		TermPart([]string{"1", "B6"}).
+		Campus([]string{"9", "1DT"}).
+		Attributes([]string{"060"}).
+		Instructor([]uint64{27957, 27961}).
+		StartTime(9*time.Hour+30*time.Minute).
+		EndTime(14*time.Hour+45*time.Minute).
+		Credits(3, 4).
+		CourseNumbers(3000, 3999).
+		Offset(16).
+		MaxResults(8)
+
+	want := map[string]string{
+		"txt_subject":                "CS,MAT",
+		"txt_courseTitle":            "Data Structures",
+		"txt_keywordlike":            `intro "machine learning"`,
+		"txt_keyword":                "neural networks",
+		"chk_open_only":              "true",
+		"txt_partOfTerm":             "1,B6",
+		"txt_campus":                 "9,1DT",
+		"txt_attribute":              "060",
+		"txt_instructor":             "27957,27961",
+		"select_start_hour":          "9",
+		"select_start_min":           "30",
+		"select_start_ampm":          "AM",
+		"select_end_hour":            "2",
+		"select_end_min":             "45",
+		"select_end_ampm":            "PM",
+		"txt_credithourlow":          "3",
+		"txt_credithourhigh":         "4",
+		"txt_course_number_range":    "3000",
+		"txt_course_number_range_to": "3999",
+		"pageOffset":                 "16",
+		"pageMaxSize":                "8",
+	}
+
+	got := query.Paramify()
+	if !reflect.DeepEqual(got, want) {
+		for key, value := range want {
+			if got[key] != value {
+				t.Errorf("Paramify()[%q] = %q, want %q", key, got[key], value)
+			}
+		}
+		for key := range got {
+			if _, ok := want[key]; !ok {
+				t.Errorf("Paramify() has unexpected key %q", key)
+			}
+		}
+	}
+}
+
+func TestParamifyEmpty(t *testing.T) {
+	want := map[string]string{"pageOffset": "0", "pageMaxSize": "8"}
+	if got := NewQuery().Paramify(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Paramify() = %v, want %v", got, want)
+	}
+}
+
+func TestParseQueryRoundTrip(t *testing.T) {
+	queries := map[string]*Query{
+		"empty": NewQuery(),
+		"every field": NewQuery().
+			Term("202510").
+			Subjects([]string{"CS", "MAT"}).
+			Title(`Data, "Structures" & Algorithms`).
+			Keywords([]string{"intro", "machine learning"}).
+			KeywordExact("neural, networks").
+			OpenOnly(true).
+			TermPart([]string{"1", "B6"}).
+			Campus([]string{"9", "1DT"}).
+			InstructionalMethod([]string{"HB", "OA"}).
+			Attributes([]string{"060", "010"}).
+			Instructor([]uint64{27957, 27961}).
+			StartTime(9*time.Hour+30*time.Minute).
+			EndTime(14*time.Hour+45*time.Minute).
+			Credits(1, 4).
+			CourseNumbers(3000, 3999).
+			Offset(16).
+			MaxResults(25),
+		"closed sections":  NewQuery().Subject("CS").OpenOnly(false),
+		"min credits only": NewQuery().MinCredits(3),
+		"max credits only": NewQuery().MaxCredits(3),
+	}
+
+	for name, query := range queries {
+		raw := query.String()
+		parsed, err := ParseQuery(raw)
+		if err != nil {
+			t.Errorf("%s: ParseQuery(%q) failed: %v", name, raw, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(parsed, query) {
+			t.Errorf("%s: ParseQuery(%q) = %s, want %s", name, raw, parsed, query)
+		}
+		if parsed.String() != raw {
+			t.Errorf("%s: round trip changed the query from %q to %q", name, raw, parsed.String())
+		}
+		if parsed.Key() != query.Key() {
+			t.Errorf("%s: round trip changed the key", name)
+		}
+	}
+}
+
+func TestParseQueryInvalid(t *testing.T) {
+	invalid := []string{
+		"subject",
+		"term=2025, maxResults=8",
+		"color=blue, maxResults=8",
+		"openOnly=maybe, maxResults=8",
+		"instructor=1,x, maxResults=8",
+		"startTime=9, maxResults=8",
+		"courseNumberRange=3000, maxResults=8",
+		`title="unterminated, maxResults=8`,
+		`title="Algorithms"maxResults=8`,
+	}
+
+	for _, raw := range invalid {
+		if _, err := ParseQuery(raw); err == nil {
+			t.Errorf("ParseQuery(%q) succeeded, want an error", raw)
+		}
+	}
+}
@@ -1,52 +1,107 @@
 package main
 
 import (
+	"fmt"
+	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	log "github.com/rs/zerolog/log"
+	"github.com/samber/lo"
 )
 
-func setup() {
-	// Makes the initial requests that sets up the session cookies for the rest of the application
+const (
+	// SetupAttempts is the number of times the session setup requests are made before giving up
+	SetupAttempts = 4
+	// SetupBackoff is the delay before the first retry of the session setup, doubling after each attempt
+	SetupBackoff = 2 * time.Second
+)
+
+// setup makes the initial requests that set up the session cookies for the rest of the application.
+// The requests are retried with backoff until the required cookies are set.
+func setup() error {
 	log.Info().Msg("Setting up session...")
 
+	baseUrlParsed, err := url.Parse(baseURL)
+	if err != nil {
+		log.Fatal().Stack().Str("baseURL", baseURL).Err(err).Msg("Failed to parse baseURL")
+	}
+
 	request_queue := []string{
 		"/registration/registration",
 		"/selfServiceMenu/data",
 	}
 
-	for _, path := range request_queue {
-		req := BuildRequest("GET", path, nil)
-		DoRequest(req)
-	}
+	backoff := SetupBackoff
+	var missing []string
+	for attempt := 1; attempt <= SetupAttempts; attempt++ {
+		for _, path := range request_queue {
+			req := BuildRequest(ctx, "GET", path, nil)
+			DoRequest(req)
+		}
 
-	// Validate that cookies were set
-	baseUrlParsed, err := url.Parse(baseURL)
-	if err != nil {
-		log.Fatal().Stack().Str("baseURL", baseURL).Err(err).Msg("Failed to parse baseURL")
-	}
+		// Dry runs never receive cookies
+		if dryRun {
+			return nil
+		}
 
-	current_cookies := client.Jar.Cookies(baseUrlParsed)
-	required_cookies := map[string]bool{
-		"JSESSIONID": false,
-		"SSB_COOKIE": false,
-	}
+		// Validate that cookies were set
+		missing = missingCookies(client.Jar.Cookies(baseUrlParsed), "JSESSIONID", "SSB_COOKIE")
+		if len(missing) == 0 {
+			log.Debug().Int("attempt", attempt).Msg("All required cookies set, session setup complete")
 
-	for _, cookie := range current_cookies {
-		_, present := required_cookies[cookie.Name]
-		// Check if this cookie is required
-		if present {
-			required_cookies[cookie.Name] = true
+			if bannerUsername != "" {
+				return login()
+			}
+			return nil
 		}
-	}
 
-	// Check if all required cookies were set
-	for cookieName, cookie_set := range required_cookies {
-		if !cookie_set {
-			log.Warn().Str("cookieName", cookieName).Msg("Required cookie not set")
+		if attempt < SetupAttempts {
+			log.Warn().Strs("missing", missing).Int("attempt", attempt).Dur("backoff", backoff).Msg("Required cookies not set, retrying session setup")
+			time.Sleep(backoff)
+			backoff *= 2
 		}
 	}
-	log.Debug().Msg("All required cookies set, session setup complete")
 
 	// TODO: Validate that the session allows access to termSelection
+	return fmt.Errorf("required cookies not set after %d attempts: %v", SetupAttempts, missing)
+}
+
+// missingCookies returns the names of the required cookies not present
+func missingCookies(cookies []*http.Cookie, required ...string) []string {
+	return lo.Filter(required, func(name string, _ int) bool {
+		return !lo.ContainsBy(cookies, func(cookie *http.Cookie) bool {
+			return cookie.Name == name
+		})
+	})
+}
+
+// login authenticates the session with the configured credentials, for Banner instances that gate class search behind a login.
+// The credentials are posted as a form; Banner redirects back to the login page if they are rejected.
+func login() error {
+	form := url.Values{}
+	form.Set("username", bannerUsername)
+	form.Set("password", bannerPassword)
+
+	req := BuildRequestWithBody(ctx, "POST", bannerLoginPath, nil, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := DoRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to log in: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		return fmt.Errorf("login failed with status code: %d", res.StatusCode)
+	}
+
+	// A rejected login is redirected back to the login page, while an accepted one continues elsewhere
+	if res.Request != nil && res.Request.Method == "GET" && strings.Contains(res.Request.URL.Path, "/login") {
+		return fmt.Errorf("login was rejected, check BANNER_USERNAME & BANNER_PASSWORD")
+	}
+
+	log.Info().Str("username", bannerUsername).Msg("Logged in to Banner")
+	return nil
 }
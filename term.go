@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -24,13 +26,7 @@ type Term struct {
 	Season uint8
 }
 
-var (
-	SpringRange, SummerRange, FallRange YearDayRange
-)
-
 func init() {
-	SpringRange, SummerRange, FallRange = GetYearDayRange(uint16(time.Now().Year()))
-
 	currentTerm, nextTerm := GetCurrentTerm(time.Now())
 	log.Debug().Str("CurrentTerm", fmt.Sprintf("%+v", currentTerm)).Str("NextTerm", fmt.Sprintf("%+v", nextTerm)).Msg("GetCurrentTerm")
 }
@@ -65,38 +61,73 @@ func GetYearDayRange(year uint16) (YearDayRange, YearDayRange, YearDayRange) {
 		}
 }
 
+var (
+	// yearDayRanges caches the term ranges of each year seen, so a long-running process never uses ranges from another year
+	yearDayRanges     = map[uint16][3]YearDayRange{}
+	yearDayRangesLock sync.Mutex
+)
+
+// GetCachedYearDayRange is like GetYearDayRange, but only computes the ranges once for each year
+func GetCachedYearDayRange(year uint16) (YearDayRange, YearDayRange, YearDayRange) {
+	yearDayRangesLock.Lock()
+	defer yearDayRangesLock.Unlock()
+
+	ranges, ok := yearDayRanges[year]
+	if !ok {
+		ranges[0], ranges[1], ranges[2] = GetYearDayRange(year)
+		yearDayRanges[year] = ranges
+	}
+	return ranges[0], ranges[1], ranges[2]
+}
+
 // GetCurrentTerm returns the current term, and the next term. Only the first term is nillable.
 // YearDay ranges are inclusive of the start, and exclusive of the end.
 func GetCurrentTerm(now time.Time) (*Term, *Term) {
+	// Compare in the same timezone as the ranges, so days aren't shifted by UTC
+	now = now.In(CentralTimeLocation)
 	year := uint16(now.Year())
 	dayOfYear := uint16(now.YearDay())
 
+	// Ranges are computed for the given year, as day-of-year boundaries shift by one after February 29th in leap years
+	springRange, summerRange, fallRange := GetCachedYearDayRange(year)
+
 	// Fall of 2024 => 202410
 	// Spring of 2024 => 202420
 	// Fall of 2025 => 202510
 	// Summer of 2025 => 202530
 
-	if (dayOfYear < SpringRange.Start) || (dayOfYear >= FallRange.End) {
+	if (dayOfYear < springRange.Start) || (dayOfYear >= fallRange.End) {
 		// Fall over, Spring not yet begun
 		return nil, &Term{Year: year + 1, Season: Spring}
-	} else if (dayOfYear >= SpringRange.Start) && (dayOfYear < SpringRange.End) {
+	} else if (dayOfYear >= springRange.Start) && (dayOfYear < springRange.End) {
 		// Spring
 		return &Term{Year: year, Season: Spring}, &Term{Year: year, Season: Summer}
-	} else if dayOfYear < SummerRange.Start {
+	} else if dayOfYear < summerRange.Start {
 		// Spring over, Summer not yet begun
 		return nil, &Term{Year: year, Season: Summer}
-	} else if (dayOfYear >= SummerRange.Start) && (dayOfYear < SummerRange.End) {
+	} else if (dayOfYear >= summerRange.Start) && (dayOfYear < summerRange.End) {
 		// Summer
 		return &Term{Year: year, Season: Summer}, &Term{Year: year, Season: Fall}
-	} else if dayOfYear < FallRange.Start {
+	} else if dayOfYear < fallRange.Start {
 		// Summer over, Fall not yet begun
 		return nil, &Term{Year: year + 1, Season: Fall}
-	} else if (dayOfYear >= FallRange.Start) && (dayOfYear < FallRange.End) {
+	} else if (dayOfYear >= fallRange.Start) && (dayOfYear < fallRange.End) {
 		// Fall
 		return &Term{Year: year + 1, Season: Fall}, nil
 	}
 
-	panic(fmt.Sprintf("Impossible Code Reached (dayOfYear: %d)", dayOfYear))
+	// Only reachable if the ranges are misconfigured (e.g. overlapping), so guess the most recently started term rather than crashing
+	log.Error().Uint16("dayOfYear", dayOfYear).Str("ranges", fmt.Sprintf("%+v %+v %+v", springRange, summerRange, fallRange)).Msg("Day of year matched no term range, guessing the most recent term")
+	switch {
+	case dayOfYear >= fallRange.Start:
+		return &Term{Year: year + 1, Season: Fall}, &Term{Year: year + 1, Season: Spring}
+	case dayOfYear >= summerRange.Start:
+		return &Term{Year: year, Season: Summer}, &Term{Year: year + 1, Season: Fall}
+	case dayOfYear >= springRange.Start:
+		return &Term{Year: year, Season: Spring}, &Term{Year: year, Season: Summer}
+	default:
+		return &Term{Year: year, Season: Fall}, &Term{Year: year, Season: Spring}
+	}
 }
 
 // ParseTerm converts a Banner term code to a Term struct
@@ -135,8 +166,45 @@ func (term Term) ToString() string {
 	return fmt.Sprintf("%d%s", term.Year, season)
 }
 
+// seasonNames maps each season to its human readable name
+var seasonNames = map[uint8]string{
+	Spring: "Spring",
+	Summer: "Summer",
+	Fall:   "Fall",
+}
+
+// HumanName returns the friendly name of the term (e.g. "Fall 2024").
+// Term years are academic years, so Fall occurs in the calendar year before (Fall 2024 => 202510).
+func (term Term) HumanName() string {
+	year := term.Year
+	if term.Season == Fall {
+		year--
+	}
+
+	return fmt.Sprintf("%s %d", seasonNames[term.Season], year)
+}
+
+// Code returns the numeric Banner term code (e.g. 202510)
+func (term Term) Code() int {
+	code, _ := strconv.Atoi(term.ToString())
+	return code
+}
+
+// termCodePattern matches a valid Banner term code (e.g. 202510)
+var termCodePattern = regexp.MustCompile(`^\d{4}(10|20|30)$`)
+
+// IsValidTermCode checks if the given string is a valid Banner term code
+func IsValidTermCode(code string) bool {
+	return termCodePattern.MatchString(code)
+}
+
 // Default chooses the default term, which is the current term if it exists, otherwise the next term.
+// If FORCE_TERM is set, that term is always chosen instead.
 func Default(t time.Time) Term {
+	if forcedTerm != nil {
+		return *forcedTerm
+	}
+
 	currentTerm, nextTerm := GetCurrentTerm(t)
 	if currentTerm == nil {
 		return *nextTerm
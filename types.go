@@ -8,6 +8,7 @@ import (
 	"time"
 
 	log "github.com/rs/zerolog/log"
+	"github.com/samber/lo"
 )
 
 const JsonContentType = "application/json"
@@ -57,7 +58,7 @@ type MeetingTimeResponse struct {
 		CreditHourSession float64 `json:"creditHourSession"`
 		// The number of hours per week this class meets (e.g. 2.5)
 		HoursWeek float64 `json:"hoursWeek"`
-		// Unknown meaning - e.g. AFF, AIN, AHB, FFF, AFF, EFF, DFF, IFF, EHB, JFF, KFF, BFF, BIN
+		// Partially decoded, see ScheduleTypeDescription - e.g. AFF, AIN, AHB, FFF, AFF, EFF, DFF, IFF, EHB, JFF, KFF, BFF, BIN
 		MeetingScheduleType string `json:"meetingScheduleType"`
 		// The short identifier for the meeting type (e.g. FF, HB, OS, OA)
 		MeetingType string `json:"meetingType"`
@@ -84,11 +85,11 @@ type MeetingTimeResponse struct {
 func (m *MeetingTimeResponse) String() string {
 	switch m.MeetingTime.MeetingType {
 	case "HB":
-		return fmt.Sprintf("%s\nHybrid %s", m.TimeString(), m.PlaceString())
+		return fmt.Sprintf("%s\nHybrid %s", m.TimeString(), m.PlaceLink())
 	case "H2":
-		return fmt.Sprintf("%s\nHybrid %s", m.TimeString(), m.PlaceString())
+		return fmt.Sprintf("%s\nHybrid %s", m.TimeString(), m.PlaceLink())
 	case "H1":
-		return fmt.Sprintf("%s\nHybrid %s", m.TimeString(), m.PlaceString())
+		return fmt.Sprintf("%s\nHybrid %s", m.TimeString(), m.PlaceLink())
 	case "OS":
 		return fmt.Sprintf("%s\nOnline Only", m.TimeString())
 	case "OA":
@@ -98,7 +99,7 @@ func (m *MeetingTimeResponse) String() string {
 	case "ID":
 		return "To Be Arranged"
 	case "FF":
-		return fmt.Sprintf("%s\n%s", m.TimeString(), m.PlaceString())
+		return fmt.Sprintf("%s\n%s", m.TimeString(), m.PlaceLink())
 	}
 
 	// TODO: Add error log
@@ -128,6 +129,19 @@ func (m *MeetingTimeResponse) PlaceString() string {
 	return fmt.Sprintf("%s | %s | %s %s", mt.CampusDescription, mt.BuildingDescription, mt.Building, mt.Room)
 }
 
+// PlaceLink is like PlaceString, but links the building to a map if one is configured.
+// The result contains Markdown, and should only be used within Discord messages.
+func (m *MeetingTimeResponse) PlaceLink() string {
+	mt := m.MeetingTime
+
+	mapURL, ok := BuildingMapURL(mt.Building)
+	if mt.Room == "" || !ok {
+		return m.PlaceString()
+	}
+
+	return fmt.Sprintf("%s | [%s](%s) | %s %s", mt.CampusDescription, mt.BuildingDescription, mapURL, mt.Building, mt.Room)
+}
+
 func (m *MeetingTimeResponse) Days() map[time.Weekday]bool {
 	days := map[time.Weekday]bool{}
 
@@ -172,9 +186,19 @@ func (m *MeetingTimeResponse) ByDay() string {
 
 const layout = "01/02/2006"
 
+// HasDateRange returns true if the meeting has both a start and end date. Arranged or TBD sections may have neither.
+func (m *MeetingTimeResponse) HasDateRange() bool {
+	return m.MeetingTime.StartDate != "" && m.MeetingTime.EndDate != ""
+}
+
 // StartDay returns the start date of the meeting time as a time.Time object
-// This is not cached and is parsed on each invocation. It may also panic without handling.
+// This is not cached and is parsed on each invocation. An empty date returns the zero time, check HasDateRange first.
+// It may also panic without handling if the date is malformed.
 func (m *MeetingTimeResponse) StartDay() time.Time {
+	if m.MeetingTime.StartDate == "" {
+		return time.Time{}
+	}
+
 	t, err := time.Parse(layout, m.MeetingTime.StartDate)
 	if err != nil {
 		log.Panic().Stack().Err(err).Str("raw", m.MeetingTime.StartDate).Msg("Cannot parse start date")
@@ -183,8 +207,13 @@ func (m *MeetingTimeResponse) StartDay() time.Time {
 }
 
 // EndDay returns the end date of the meeting time as a time.Time object.
-// This is not cached and is parsed on each invocation. It may also panic without handling.
+// This is not cached and is parsed on each invocation. An empty date returns the zero time, check HasDateRange first.
+// It may also panic without handling if the date is malformed.
 func (m *MeetingTimeResponse) EndDay() time.Time {
+	if m.MeetingTime.EndDate == "" {
+		return time.Time{}
+	}
+
 	t, err := time.Parse(layout, m.MeetingTime.EndDate)
 	if err != nil {
 		log.Panic().Stack().Err(err).Str("raw", m.MeetingTime.EndDate).Msg("Cannot parse end date")
@@ -225,7 +254,12 @@ func (m *MeetingTimeResponse) EndTime() *NaiveTime {
 }
 
 // Converts the meeting time to a string that satisfies the iCalendar RRule format
+// Meetings without a defined date range have no recurrence, so an empty string is returned.
 func (m *MeetingTimeResponse) RRule() string {
+	if !m.HasDateRange() {
+		return ""
+	}
+
 	sb := strings.Builder{}
 
 	sb.WriteString("FREQ=WEEKLY;")
@@ -248,6 +282,30 @@ type SearchResult struct {
 	Data []Course `json:"data"`
 }
 
+// Page returns the 1-based page number of the result, from the offset & page size Banner reports
+func (result *SearchResult) Page() int {
+	if result.PageMaxSize <= 0 {
+		return 1
+	}
+	return result.PageOffset/result.PageMaxSize + 1
+}
+
+// PageCount returns the number of pages needed for every result at the reported page size, at least 1
+func (result *SearchResult) PageCount() int {
+	if result.PageMaxSize <= 0 || result.TotalCount == 0 {
+		return 1
+	}
+	return (result.TotalCount + result.PageMaxSize - 1) / result.PageMaxSize
+}
+
+// CheckPaging returns an error if Banner did not honor the requested offset & page size
+func (result *SearchResult) CheckPaging(offset int, maxResults int) error {
+	if result.PageOffset != offset || result.PageMaxSize != maxResults {
+		return fmt.Errorf("requested offset %d & page size %d, but received offset %d & page size %d", offset, maxResults, result.PageOffset, result.PageMaxSize)
+	}
+	return nil
+}
+
 type Course struct {
 	// A internal identifier not used outside of the Banner system
 	Id int `json:"id"`
@@ -295,26 +353,100 @@ type Course struct {
 	LinkIdentifier      *string `json:"linkIdentifier"`
 	IsSectionLinked     bool    `json:"isSectionLinked"`
 	// A combination of the subject and course number (e.g. subject=CS, courseNumber=3443 => "CS3443")
-	SubjectCourse                  string  `json:"subjectCourse"`
-	ReservedSeatSummary            *string `json:"reservedSeatSummary"`
-	InstructionalMethod            string  `json:"instructionalMethod"`
-	InstructionalMethodDescription string  `json:"instructionalMethodDescription"`
-	SectionAttributes              []struct {
-		// A internal API class identifier used by Banner
-		Class                 string `json:"class"`
-		CourseReferenceNumber string `json:"courseReferenceNumber"`
-		// UPPR, ZIEP, AIS, LEWR, ZZSL, 090, GRAD, ZZTL, 020, BU, CLEP
-		Code string `json:"code"`
-		// Seems to be the fully qualified meaning of the Code (Upper, Intensive English Program...)
-		Description string `json:"description"`
-		TermCode    string `json:"termCode"`
-		// Unknown; always false
-		IsZtcAttribute bool `json:"isZTCAttribute"`
-	} `json:"sectionAttributes"`
-	Faculty         []FacultyItem         `json:"faculty"`
-	MeetingsFaculty []MeetingTimeResponse `json:"meetingsFaculty"`
+	SubjectCourse                  string                `json:"subjectCourse"`
+	ReservedSeatSummary            *string               `json:"reservedSeatSummary"`
+	InstructionalMethod            string                `json:"instructionalMethod"`
+	InstructionalMethodDescription string                `json:"instructionalMethodDescription"`
+	SectionAttributes              []SectionAttribute    `json:"sectionAttributes"`
+	Faculty                        []FacultyItem         `json:"faculty"`
+	MeetingsFaculty                []MeetingTimeResponse `json:"meetingsFaculty"`
+}
+
+type SectionAttribute struct {
+	// A internal API class identifier used by Banner
+	Class                 string `json:"class"`
+	CourseReferenceNumber string `json:"courseReferenceNumber"`
+	// UPPR, ZIEP, AIS, LEWR, ZZSL, 090, GRAD, ZZTL, 020, BU, CLEP
+	Code string `json:"code"`
+	// Seems to be the fully qualified meaning of the Code (Upper, Intensive English Program...)
+	Description string `json:"description"`
+	TermCode    string `json:"termCode"`
+	// Unknown; always false
+	IsZtcAttribute bool `json:"isZTCAttribute"`
 }
 
 func (course Course) MarshalBinary() ([]byte, error) {
 	return json.Marshal(course)
 }
+
+// CreditString returns the credit hours of the course, accounting for variable-credit courses (e.g. "3", "1-6", "3 or 4")
+func (course Course) CreditString() string {
+	if course.CreditHourLow == nil || course.CreditHourHigh == nil || *course.CreditHourLow == *course.CreditHourHigh {
+		if course.CreditHourLow != nil && course.CreditHours == 0 {
+			return strconv.Itoa(*course.CreditHourLow)
+		}
+		return strconv.Itoa(course.CreditHours)
+	}
+
+	if course.CreditHourIndicator != nil && *course.CreditHourIndicator == "OR" {
+		return fmt.Sprintf("%d or %d", *course.CreditHourLow, *course.CreditHourHigh)
+	}
+
+	return fmt.Sprintf("%d-%d", *course.CreditHourLow, *course.CreditHourHigh)
+}
+
+// CreditRange returns the minimum & maximum credit hours of the course, which are equal for fixed-credit courses
+func (course Course) CreditRange() (int, int) {
+	if course.CreditHourLow == nil || course.CreditHourHigh == nil || *course.CreditHourLow == *course.CreditHourHigh {
+		if course.CreditHourLow != nil && course.CreditHours == 0 {
+			return *course.CreditHourLow, *course.CreditHourLow
+		}
+		return course.CreditHours, course.CreditHours
+	}
+
+	return *course.CreditHourLow, *course.CreditHourHigh
+}
+
+// Locations lists each distinct place the course meets, with the days it meets there (e.g. ["MW: SP1 3.01.08", "F: MB 2.01.02"]).
+// Meetings without a room (e.g. online) are listed as "Online", without days.
+func (course Course) Locations() []string {
+	places := []string{}
+	days := map[string]map[time.Weekday]bool{}
+
+	for _, meeting := range course.MeetingsFaculty {
+		mt := meeting.MeetingTime
+		place := "Online"
+		if mt.Room != "" {
+			place = fmt.Sprintf("%s %s", mt.Building, mt.Room)
+		}
+
+		if _, seen := days[place]; !seen {
+			places = append(places, place)
+			days[place] = map[time.Weekday]bool{}
+		}
+		for _, day := range meetingWeekdays(meeting) {
+			days[place][day] = true
+		}
+	}
+
+	return lo.Map(places, func(place string, _ int) string {
+		if place == "Online" || len(days[place]) == 0 {
+			return place
+		}
+		return fmt.Sprintf("%s: %s", WeekdaysToString(days[place]), place)
+	})
+}
+
+// StatusEmoji returns an indicator of the section's state: green when seats are open,
+// yellow when only the waitlist has room, and red when both are full.
+func (course Course) StatusEmoji() string {
+	if course.OpenSection && course.SeatsAvailable > 0 {
+		return "🟢"
+	}
+
+	if course.WaitCount < course.WaitCapacity {
+		return "🟡"
+	}
+
+	return "🔴"
+}